@@ -1,6 +1,14 @@
 package stream
 
-import "context"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrOverweight marks a value that is heavier than the BatchBy limit by itself.
+var ErrOverweight = errors.New("stream: value exceeds the maximum batch weight")
 
 // Batch is a pipeline accumulator.
 //
@@ -9,32 +17,185 @@ import "context"
 // function enables streams to take advantage of that by accumulating
 // incoming data and returning a channel of results of slices of the data.
 //
-// note: to balance between performance and control, a select is included at the
-// send, but nowhere else.
+// note: every send, including errors and the final partial batch, honors the
+// context. a cancelled Batch discards its accumulator and drains the input in
+// the background.
+//
+// errors are forwarded inline unless WithErrorPolicy says otherwise. FailFast
+// discards the accumulator, and CollectErrors sends the joined errors after
+// the final batch.
 func Batch[T any](
 	input <-chan *Result[T],
 	batchSize int,
 	opts ...Option,
 ) <-chan *Result[[]T] {
+	policy := newOptions(opts...).errorPolicy
+
 	return Stream(func(ctx context.Context, output chan<- *Result[[]T]) {
+		errs := &errorHandler[[]T]{ctx: ctx, output: output, policy: policy}
+		defer errs.flush()
+
 		var accumulator []T
 		for result := range input {
 			if result.Error != nil {
-				output <- NewResult[[]T](nil, result.Error)
-			} else {
-				if len(accumulator) >= batchSize {
-					select {
-					case <-ctx.Done():
+				if !errs.handle(NewResult[[]T](nil, result.Error)) {
+					go Drain(input)
+					return
+				}
+				continue
+			}
+			if len(accumulator) >= batchSize {
+				if !send(ctx, output, NewResult(accumulator, nil)) {
+					go Drain(input)
+					return
+				}
+				accumulator = nil
+			}
+			accumulator = append(accumulator, result.Value)
+		}
+		if len(accumulator) > 0 {
+			send(ctx, output, NewResult(accumulator, nil))
+		}
+	}, opts...)
+}
+
+// BatchWithTimeout is a Batch that also flushes a partial batch once its oldest
+// value has waited maxLatency, so a slow trickle of data still reaches the
+// consumer in a timely manner.
+//
+// unlike Batch, a full batch is sent as soon as it reaches batchSize rather than
+// when the next value arrives.
+//
+// note: the WithClock option replaces the timer source, which allows tests to
+// control the passage of time. WithErrorPolicy applies as it does to Batch.
+func BatchWithTimeout[T any](
+	input <-chan *Result[T],
+	batchSize int,
+	maxLatency time.Duration,
+	opts ...Option,
+) <-chan *Result[[]T] {
+	options := newOptions(opts...)
+	clock := options.clock
+
+	return Stream(func(ctx context.Context, output chan<- *Result[[]T]) {
+		errs := &errorHandler[[]T]{
+			ctx:    ctx,
+			output: output,
+			policy: options.errorPolicy,
+		}
+
+		var accumulator []T
+		// the deadline is only armed while the accumulator holds values.
+		var deadline <-chan time.Time
+
+		flush := func() bool {
+			batch := accumulator
+			accumulator, deadline = nil, nil
+			return send(ctx, output, NewResult(batch, nil))
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				go Drain(input)
+				return
+			case <-deadline:
+				if !flush() {
+					go Drain(input)
+					return
+				}
+			case result, ok := <-input:
+				if !ok {
+					if len(accumulator) > 0 {
+						flush()
+					}
+					errs.flush()
+					return
+				}
+				if result.Error != nil {
+					if !errs.handle(NewResult[[]T](nil, result.Error)) {
+						go Drain(input)
 						return
-					case output <- NewResult(accumulator, nil):
 					}
-					accumulator = nil
+					continue
+				}
+				if len(accumulator) == 0 {
+					deadline = clock.After(maxLatency)
 				}
 				accumulator = append(accumulator, result.Value)
+				if len(accumulator) >= batchSize && !flush() {
+					go Drain(input)
+					return
+				}
+			}
+		}
+	}, opts...)
+}
+
+// BatchBy is a Batch that groups values by a cumulative weight rather than by
+// count. the weigh function returns the cost of a value, such as its encoded
+// size in bytes, and a batch is sent before adding a value would push it over
+// maxWeight.
+//
+// a value that is heavier than maxWeight on its own can never fit, so it is
+// sent alone as an error Result wrapping ErrOverweight. the Result still holds
+// the value, should the consumer prefer to handle it anyway. a panic in weigh
+// is sent the same way, holding a PanicError. WithErrorPolicy applies to these
+// errors as it does to those of the input.
+func BatchBy[T any](
+	input <-chan *Result[T],
+	maxWeight int,
+	weigh func(value T) int,
+	opts ...Option,
+) <-chan *Result[[]T] {
+	options := newOptions(opts...)
+
+	return Stream(func(ctx context.Context, output chan<- *Result[[]T]) {
+		errs := &errorHandler[[]T]{ctx: ctx, output: output, policy: options.errorPolicy}
+		defer errs.flush()
+
+		var accumulator []T
+		var weight int
+		for result := range input {
+			if result.Error != nil {
+				if !errs.handle(NewResult[[]T](nil, result.Error)) {
+					go Drain(input)
+					return
+				}
+				continue
+			}
+
+			valueWeight, err := protect(options.recoverPanics, func() (int, error) {
+				return weigh(result.Value), nil
+			})
+			if err != nil {
+				if !errs.handle(NewResult([]T{result.Value}, err)) {
+					go Drain(input)
+					return
+				}
+				continue
+			}
+			if valueWeight > maxWeight {
+				err := fmt.Errorf("%w: %d > %d", ErrOverweight, valueWeight, maxWeight)
+				if !errs.handle(NewResult([]T{result.Value}, err)) {
+					go Drain(input)
+					return
+				}
+				continue
+			}
+
+			if weight+valueWeight > maxWeight {
+				if !send(ctx, output, NewResult(accumulator, nil)) {
+					go Drain(input)
+					return
+				}
+				accumulator, weight = nil, 0
 			}
+			accumulator = append(accumulator, result.Value)
+			weight += valueWeight
 		}
 		if len(accumulator) > 0 {
-			output <- NewResult(accumulator, nil)
+			send(ctx, output, NewResult(accumulator, nil))
 		}
 	}, opts...)
 }
@@ -3,6 +3,7 @@ package stream
 import (
 	"context"
 	"errors"
+	"runtime"
 	"testing"
 	"time"
 
@@ -115,12 +116,8 @@ func TestBatch(t *testing.T) {
 		cancel()
 		time.Sleep(1 * time.Millisecond)
 
-		// the cancel signal doesn't prevent the final batch from being sent.
+		// the cancel signal prevents the final batch from being sent.
 		output, ok := <-batched
-		require.True(t, ok)
-		require.Equal(t, &Result[[]int]{[]int{9, 10}, nil}, output)
-
-		output, ok = <-batched
 		require.False(t, ok)
 		require.Nil(t, output)
 	})
@@ -152,3 +149,181 @@ func TestBatch(t *testing.T) {
 		require.Nil(t, output)
 	})
 }
+
+func TestBatchErrorPolicy(t *testing.T) {
+	err1, err2 := errors.New("first"), errors.New("second")
+	src := func() <-chan *Result[int] {
+		return Stream(func(_ context.Context, output chan<- *Result[int]) {
+			output <- NewResult(1, nil)
+			output <- NewResult(0, err1)
+			output <- NewResult(2, nil)
+			output <- NewResult(3, nil)
+			output <- NewResult(0, err2)
+			output <- NewResult(4, nil)
+		})
+	}
+
+	t.Run("fail fast", func(t *testing.T) {
+		batched := Batch(src(), 2, WithErrorPolicy(FailFast))
+
+		require.Equal(t, &Result[[]int]{nil, err1}, <-batched)
+		validateChannel(t, nil, false, batched)
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		batched := BatchWithTimeout(src(), 2, time.Hour,
+			WithErrorPolicy(SkipErrors))
+
+		require.Equal(t, NewResult([]int{1, 2}, nil), <-batched)
+		require.Equal(t, NewResult([]int{3, 4}, nil), <-batched)
+		validateChannel(t, nil, false, batched)
+	})
+
+	t.Run("collect", func(t *testing.T) {
+		batched := BatchBy(src(), 2, func(int) int { return 1 },
+			WithErrorPolicy(CollectErrors))
+
+		require.Equal(t, NewResult([]int{1, 2}, nil), <-batched)
+		require.Equal(t, NewResult([]int{3, 4}, nil), <-batched)
+		result := <-batched
+		require.Nil(t, result.Value)
+		require.ErrorIs(t, result.Error, err1)
+		require.ErrorIs(t, result.Error, err2)
+		validateChannel(t, nil, false, batched)
+	})
+}
+
+func TestBatchWithTimeout(t *testing.T) {
+	t.Run("flush on size", func(t *testing.T) {
+		src := Stream(func(_ context.Context, output chan<- *Result[int]) {
+			for value := range 5 {
+				output <- NewResult(value, nil)
+			}
+		})
+		batched := BatchWithTimeout(src, 2, time.Hour)
+
+		require.Equal(t, NewResult([]int{0, 1}, nil), <-batched)
+		require.Equal(t, NewResult([]int{2, 3}, nil), <-batched)
+		require.Equal(t, NewResult([]int{4}, nil), <-batched)
+		validateChannel(t, nil, false, batched)
+	})
+
+	t.Run("flush on latency", func(t *testing.T) {
+		clock := newFakeClock()
+		src := make(chan *Result[int])
+		defer close(src)
+		batched := BatchWithTimeout(src, 3, time.Second, WithClock(clock))
+
+		src <- NewResult(1, nil)
+		clock.WaitForTimers(t, 1)
+		src <- NewResult(2, nil)
+
+		// the deadline belongs to the oldest value.
+		clock.Advance(999 * time.Millisecond)
+		select {
+		case batch := <-batched:
+			require.FailNow(t, "flushed early", batch)
+		case <-time.After(5 * time.Millisecond):
+		}
+
+		clock.Advance(time.Millisecond)
+		require.Equal(t, NewResult([]int{1, 2}, nil), <-batched)
+
+		// the next value starts a new deadline.
+		src <- NewResult(3, nil)
+		clock.WaitForTimers(t, 1)
+		clock.Advance(time.Second)
+		require.Equal(t, NewResult([]int{3}, nil), <-batched)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		err := errors.New("generic error")
+		src := Stream(func(_ context.Context, output chan<- *Result[int]) {
+			output <- NewResult(1, nil)
+			output <- NewResult(0, err)
+			output <- NewResult(2, nil)
+		})
+		batched := BatchWithTimeout(src, 2, time.Hour)
+
+		require.Equal(t, &Result[[]int]{nil, err}, <-batched)
+		require.Equal(t, NewResult([]int{1, 2}, nil), <-batched)
+		validateChannel(t, nil, false, batched)
+	})
+
+	t.Run("cancelable", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		batched := BatchWithTimeout(counter(1000), 2, time.Hour,
+			WithContext(ctx), WithBufferSize(0))
+		require.Equal(t, NewResult([]int{0, 1}, nil), <-batched)
+		cancel()
+
+		requireNoLeaks(t, baseline)
+	})
+}
+
+func TestBatchBy(t *testing.T) {
+	weigh := func(value string) int {
+		return len(value)
+	}
+
+	t.Run("weighted", func(t *testing.T) {
+		src := Stream(func(_ context.Context, output chan<- *Result[string]) {
+			for _, value := range []string{"ab", "cd", "efg", "h", "ijklm", "n"} {
+				output <- NewResult(value, nil)
+			}
+		})
+		batched := BatchBy(src, 5, weigh)
+
+		require.Equal(t, NewResult([]string{"ab", "cd"}, nil), <-batched)
+		require.Equal(t, NewResult([]string{"efg", "h"}, nil), <-batched)
+		require.Equal(t, NewResult([]string{"ijklm"}, nil), <-batched)
+		require.Equal(t, NewResult([]string{"n"}, nil), <-batched)
+		validateChannel(t, nil, false, batched)
+	})
+
+	t.Run("overweight", func(t *testing.T) {
+		src := Stream(func(_ context.Context, output chan<- *Result[string]) {
+			output <- NewResult("ab", nil)
+			output <- NewResult("abcdefg", nil)
+			output <- NewResult("cd", nil)
+		})
+		batched := BatchBy(src, 5, weigh)
+
+		// the overweight value doesn't interrupt the batch in progress.
+		result := <-batched
+		require.ErrorIs(t, result.Error, ErrOverweight)
+		require.Equal(t, []string{"abcdefg"}, result.Value)
+
+		require.Equal(t, NewResult([]string{"ab", "cd"}, nil), <-batched)
+		validateChannel(t, nil, false, batched)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		err := errors.New("generic error")
+		src := Stream(func(_ context.Context, output chan<- *Result[string]) {
+			output <- NewResult("ab", nil)
+			output <- NewResult("", err)
+			output <- NewResult("cd", nil)
+		})
+		batched := BatchBy(src, 5, weigh)
+
+		require.Equal(t, &Result[[]string]{nil, err}, <-batched)
+		require.Equal(t, NewResult([]string{"ab", "cd"}, nil), <-batched)
+		validateChannel(t, nil, false, batched)
+	})
+
+	t.Run("cancelable", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		batched := BatchBy(counter(1000), 2,
+			func(int) int { return 1 },
+			WithContext(ctx), WithBufferSize(0))
+		require.Equal(t, NewResult([]int{0, 1}, nil), <-batched)
+		cancel()
+
+		requireNoLeaks(t, baseline)
+	})
+}
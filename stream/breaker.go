@@ -0,0 +1,222 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned, without calling the protected function, while a
+// CircuitBreaker is open.
+var ErrCircuitOpen = errors.New("stream: circuit breaker is open")
+
+// CircuitState is the state of a CircuitBreaker.
+type CircuitState int
+
+const (
+	// CircuitClosed allows every call and tracks the failure rate.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails every call fast until the cooldown has passed.
+	CircuitOpen
+	// CircuitHalfOpen allows a single trial call that decides whether to close
+	// or reopen the breaker.
+	CircuitHalfOpen
+)
+
+func (state CircuitState) String() string {
+	switch state {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerPolicy configures a CircuitBreaker. the zero value is usable.
+type CircuitBreakerPolicy struct {
+	// Window is the rolling period over which the failure rate is measured.
+	// defaults to 10s.
+	Window time.Duration
+	// FailureRate is the fraction of failed calls, between 0 and 1, that opens
+	// the breaker. defaults to 0.5.
+	FailureRate float64
+	// MinCalls is the number of calls within the window before the failure
+	// rate is considered. defaults to 10.
+	MinCalls int
+	// Cooldown is how long the breaker stays open before a trial call is
+	// allowed. defaults to 30s.
+	Cooldown time.Duration
+	// IsFailure reports whether an error counts against the dependency. nil
+	// counts every error except context cancellation.
+	IsFailure func(err error) bool
+	// Clock is the source of time. nil uses the wall clock.
+	Clock Clock
+}
+
+// CircuitBreaker tracks the health of a dependency. it is safe for concurrent
+// use, so a single breaker can be shared by every worker of a Processor. see
+// CircuitBreak.
+type CircuitBreaker struct {
+	policy CircuitBreakerPolicy
+
+	mu       sync.Mutex
+	state    CircuitState
+	outcomes []outcome
+	openedAt time.Time
+	trial    bool
+}
+
+// outcome is a call result recorded within the rolling window.
+type outcome struct {
+	at     time.Time
+	failed bool
+}
+
+// NewCircuitBreaker creates a closed CircuitBreaker, filling in the defaults of
+// the policy.
+func NewCircuitBreaker(policy CircuitBreakerPolicy) *CircuitBreaker {
+	if policy.Window == 0 {
+		policy.Window = 10 * time.Second
+	}
+	if policy.FailureRate == 0 {
+		policy.FailureRate = 0.5
+	}
+	if policy.MinCalls == 0 {
+		policy.MinCalls = 10
+	}
+	if policy.Cooldown == 0 {
+		policy.Cooldown = 30 * time.Second
+	}
+	if policy.IsFailure == nil {
+		policy.IsFailure = func(err error) bool {
+			return !errors.Is(err, context.Canceled)
+		}
+	}
+	if policy.Clock == nil {
+		policy.Clock = systemClock{}
+	}
+
+	return &CircuitBreaker{policy: policy}
+}
+
+// State returns the current state of the breaker.
+func (breaker *CircuitBreaker) State() CircuitState {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	if breaker.state == CircuitOpen && breaker.cooled() {
+		return CircuitHalfOpen
+	}
+	return breaker.state
+}
+
+// cooled reports whether an open breaker may allow a trial call.
+func (breaker *CircuitBreaker) cooled() bool {
+	return breaker.policy.Clock.Now().Sub(breaker.openedAt) >= breaker.policy.Cooldown
+}
+
+// allow reports whether a call may proceed, and whether that call is the trial
+// of a half-open breaker. only the trial's outcome ends the half-open state.
+func (breaker *CircuitBreaker) allow() (allowed bool, trial bool) {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	switch breaker.state {
+	case CircuitOpen:
+		if !breaker.cooled() {
+			return false, false
+		}
+		breaker.state = CircuitHalfOpen
+		breaker.trial = true
+		return true, true
+	case CircuitHalfOpen:
+		if breaker.trial {
+			return false, false
+		}
+		breaker.trial = true
+		return true, true
+	default:
+		return true, false
+	}
+}
+
+// record updates the breaker with the outcome of an allowed call. outcomes of
+// calls that began while the breaker was closed are ignored once it has opened.
+//
+// a panic counts as a failure. a trial that ends with an error that isn't a
+// failure, such as a cancellation, never tested the dependency, so the breaker
+// stays half-open for another trial.
+func (breaker *CircuitBreaker) record(trial bool, err error, panicked bool) {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	now := breaker.policy.Clock.Now()
+	failed := panicked || err != nil && breaker.policy.IsFailure(err)
+
+	if trial {
+		breaker.trial = false
+		switch {
+		case failed:
+			breaker.state, breaker.openedAt = CircuitOpen, now
+		case err == nil:
+			breaker.state = CircuitClosed
+		}
+		return
+	}
+	if breaker.state != CircuitClosed {
+		return
+	}
+
+	breaker.outcomes = append(breaker.outcomes, outcome{now, failed})
+	cutoff := now.Add(-breaker.policy.Window)
+	for len(breaker.outcomes) > 0 && !breaker.outcomes[0].at.After(cutoff) {
+		breaker.outcomes = breaker.outcomes[1:]
+	}
+	if len(breaker.outcomes) < breaker.policy.MinCalls {
+		return
+	}
+
+	failures := 0
+	for _, outcome := range breaker.outcomes {
+		if outcome.failed {
+			failures++
+		}
+	}
+	if float64(failures)/float64(len(breaker.outcomes)) >= breaker.policy.FailureRate {
+		breaker.state, breaker.openedAt = CircuitOpen, now
+		breaker.outcomes = nil
+	}
+}
+
+// CircuitBreak wraps a Transform function with a CircuitBreaker. while the
+// breaker is open, the function is skipped and ErrCircuitOpen is returned, so
+// consumers can tell skipped values from real failures.
+func CircuitBreak[T, U any](
+	breaker *CircuitBreaker,
+	transform func(ctx context.Context, input T) (U, error),
+) func(ctx context.Context, input T) (U, error) {
+	return func(ctx context.Context, input T) (U, error) {
+		allowed, trial := breaker.allow()
+		if !allowed {
+			return *new(U), ErrCircuitOpen
+		}
+
+		// the outcome is recorded even if transform panics, so a panicking
+		// trial still ends.
+		var err error
+		panicked := true
+		defer func() {
+			breaker.record(trial, err, panicked)
+		}()
+
+		var value U
+		value, err = transform(ctx, input)
+		panicked = false
+		return value, err
+	}
+}
@@ -0,0 +1,201 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	errDown := errors.New("dependency down")
+
+	// dependency fails while down is set.
+	down := true
+	dependency := func(_ context.Context, input int) (int, error) {
+		if down {
+			return 0, errDown
+		}
+		return input, nil
+	}
+
+	clock := newFakeClock()
+	breaker := NewCircuitBreaker(CircuitBreakerPolicy{
+		Window:      time.Minute,
+		FailureRate: 0.5,
+		MinCalls:    4,
+		Cooldown:    10 * time.Second,
+		Clock:       clock,
+	})
+	protected := CircuitBreak(breaker, dependency)
+
+	t.Run("opens at the failure rate", func(t *testing.T) {
+		down = false
+		for i := range 2 {
+			_, err := protected(context.Background(), i)
+			require.NoError(t, err)
+		}
+		down = true
+		_, err := protected(context.Background(), 0)
+		require.ErrorIs(t, err, errDown)
+		require.Equal(t, CircuitClosed, breaker.State())
+
+		_, err = protected(context.Background(), 0)
+		require.ErrorIs(t, err, errDown)
+		require.Equal(t, CircuitOpen, breaker.State())
+
+		_, err = protected(context.Background(), 0)
+		require.ErrorIs(t, err, ErrCircuitOpen)
+	})
+
+	t.Run("failed trial reopens", func(t *testing.T) {
+		clock.Advance(10 * time.Second)
+		require.Equal(t, CircuitHalfOpen, breaker.State())
+
+		_, err := protected(context.Background(), 0)
+		require.ErrorIs(t, err, errDown)
+		require.Equal(t, CircuitOpen, breaker.State())
+	})
+
+	t.Run("successful trial closes", func(t *testing.T) {
+		down = false
+		clock.Advance(10 * time.Second)
+
+		value, err := protected(context.Background(), 1)
+		require.NoError(t, err)
+		require.Equal(t, 1, value)
+		require.Equal(t, CircuitClosed, breaker.State())
+	})
+
+	t.Run("old outcomes expire", func(t *testing.T) {
+		down = true
+		for range 3 {
+			protected(context.Background(), 0)
+		}
+		clock.Advance(time.Minute)
+
+		down = false
+		_, err := protected(context.Background(), 0)
+		require.NoError(t, err)
+		require.Equal(t, CircuitClosed, breaker.State())
+	})
+
+	t.Run("in a stream", func(t *testing.T) {
+		breaker := NewCircuitBreaker(CircuitBreakerPolicy{MinCalls: 2})
+		down = true
+
+		src := Stream(func(_ context.Context, output chan<- *Result[int]) {
+			for i := range 4 {
+				output <- NewResult(i, nil)
+			}
+		})
+		results := Transform(src, CircuitBreak(breaker, dependency))
+
+		require.ErrorIs(t, (<-results).Error, errDown)
+		require.ErrorIs(t, (<-results).Error, errDown)
+		require.ErrorIs(t, (<-results).Error, ErrCircuitOpen)
+		require.ErrorIs(t, (<-results).Error, ErrCircuitOpen)
+		validateChannel(t, nil, false, results)
+	})
+}
+
+func TestCircuitBreakerTrial(t *testing.T) {
+	errDown := errors.New("dependency down")
+	policy := func(clock Clock) CircuitBreakerPolicy {
+		return CircuitBreakerPolicy{MinCalls: 1, Cooldown: time.Second, Clock: clock}
+	}
+	failing := func(_ context.Context, input int) (int, error) {
+		return 0, errDown
+	}
+
+	t.Run("panicking trial reopens", func(t *testing.T) {
+		clock := newFakeClock()
+		breaker := NewCircuitBreaker(policy(clock))
+		_, err := CircuitBreak(breaker, failing)(context.Background(), 0)
+		require.ErrorIs(t, err, errDown)
+		require.Equal(t, CircuitOpen, breaker.State())
+
+		clock.Advance(time.Second)
+		results := Transform(FromSlice([]int{0}), CircuitBreak(breaker,
+			func(_ context.Context, input int) (int, error) {
+				panic("trial")
+			}))
+		var panicErr *PanicError
+		require.ErrorAs(t, (<-results).Error, &panicErr)
+		require.Equal(t, CircuitOpen, breaker.State())
+
+		clock.Advance(time.Hour)
+		require.Equal(t, CircuitHalfOpen, breaker.State())
+		value, err := CircuitBreak(breaker,
+			func(_ context.Context, input int) (int, error) {
+				return input, nil
+			})(context.Background(), 1)
+		require.NoError(t, err)
+		require.Equal(t, 1, value)
+		require.Equal(t, CircuitClosed, breaker.State())
+	})
+
+	t.Run("cancelled trial stays half-open", func(t *testing.T) {
+		clock := newFakeClock()
+		breaker := NewCircuitBreaker(policy(clock))
+		_, err := CircuitBreak(breaker, failing)(context.Background(), 0)
+		require.ErrorIs(t, err, errDown)
+
+		clock.Advance(time.Second)
+		_, err = CircuitBreak(breaker, func(_ context.Context, input int) (int, error) {
+			return 0, context.Canceled
+		})(context.Background(), 0)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Equal(t, CircuitHalfOpen, breaker.State())
+
+		// the next call is a fresh trial.
+		_, err = CircuitBreak(breaker, failing)(context.Background(), 0)
+		require.ErrorIs(t, err, errDown)
+		require.Equal(t, CircuitOpen, breaker.State())
+	})
+
+	t.Run("only the trial ends half-open", func(t *testing.T) {
+		clock := newFakeClock()
+		breaker := NewCircuitBreaker(policy(clock))
+
+		// blocked makes a call that waits to be released, once it has started.
+		blocked := func() (release chan error, done chan error) {
+			started, release, done := make(chan struct{}), make(chan error), make(chan error)
+			call := CircuitBreak(breaker, func(_ context.Context, input int) (int, error) {
+				close(started)
+				return input, <-release
+			})
+			go func() {
+				_, err := call(context.Background(), 0)
+				done <- err
+			}()
+			<-started
+			return release, done
+		}
+
+		// a slow call starts while closed, then the breaker opens and cools.
+		releaseSlow, slowDone := blocked()
+		_, err := CircuitBreak(breaker, failing)(context.Background(), 0)
+		require.ErrorIs(t, err, errDown)
+		clock.Advance(time.Second)
+		releaseTrial, trialDone := blocked()
+
+		releaseSlow <- nil
+		require.NoError(t, <-slowDone)
+		require.Equal(t, CircuitHalfOpen, breaker.State())
+		_, err = CircuitBreak(breaker, failing)(context.Background(), 0)
+		require.ErrorIs(t, err, ErrCircuitOpen)
+
+		releaseTrial <- nil
+		require.NoError(t, <-trialDone)
+		require.Equal(t, CircuitClosed, breaker.State())
+	})
+}
+
+func TestCircuitState(t *testing.T) {
+	require.Equal(t, "closed", CircuitClosed.String())
+	require.Equal(t, "open", CircuitOpen.String())
+	require.Equal(t, "half-open", CircuitHalfOpen.String())
+}
@@ -0,0 +1,134 @@
+package stream
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// requireNoLeaks waits for the goroutine count to return to the baseline taken
+// before the pipeline was built.
+func requireNoLeaks(t *testing.T, baseline int) {
+	t.Helper()
+	// polled inline, since require.Eventually runs its own goroutines.
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > baseline && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	require.LessOrEqual(t, runtime.NumGoroutine(), baseline)
+}
+
+// counter is a bounded source that intentionally ignores the context, so only
+// the downstream stages can unblock it.
+func counter(n int) <-chan *Result[int] {
+	return Stream(func(_ context.Context, output chan<- *Result[int]) {
+		for i := range n {
+			output <- NewResult(i, nil)
+		}
+	}, WithBufferSize(0))
+}
+
+// each case builds a stage on unbuffered channels, reads a single value, then
+// cancels and abandons the outputs. every goroutine must still exit.
+func TestCancellationLeaks(t *testing.T) {
+	identity := func(_ context.Context, value int) (int, error) {
+		return value, nil
+	}
+
+	t.Run("Transform", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		output := Transform(counter(1000), identity,
+			WithContext(ctx), WithBufferSize(0))
+		require.Equal(t, NewResult(0, nil), <-output)
+		cancel()
+
+		requireNoLeaks(t, baseline)
+	})
+
+	t.Run("Spread", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		output := Spread(Batch(counter(1000), 10),
+			WithContext(ctx), WithBufferSize(0))
+		require.Equal(t, NewResult(0, nil), <-output)
+		cancel()
+
+		requireNoLeaks(t, baseline)
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		output := Batch(counter(1000), 2,
+			WithContext(ctx), WithBufferSize(0))
+		require.Equal(t, NewResult([]int{0, 1}, nil), <-output)
+		cancel()
+
+		requireNoLeaks(t, baseline)
+	})
+
+	t.Run("Tee", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		out1, _ := Tee(counter(1000), WithContext(ctx), WithBufferSize(0))
+		require.Equal(t, NewResult(0, nil), <-out1)
+		cancel()
+
+		requireNoLeaks(t, baseline)
+	})
+
+	t.Run("Collect", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		output := Collect(counter(1000), WithContext(ctx), WithBufferSize(0))
+		cancel()
+
+		validateChannel(t, nil, false, output)
+		requireNoLeaks(t, baseline)
+	})
+
+	t.Run("Fold", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := Fold(counter(1000), 0,
+			func(_ context.Context, total int, value int) (int, error) {
+				return total + value, nil
+			},
+			WithContext(ctx),
+		)
+		require.ErrorIs(t, err, context.Canceled)
+		requireNoLeaks(t, baseline)
+	})
+
+	t.Run("Distribute Processor Multiplex", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		output := Multiplex(
+			Processor(
+				Distribute(counter(1000), 4,
+					WithContext(ctx), WithBufferSize(0)),
+				func(int) func(context.Context, int) (int, error) {
+					return identity
+				},
+				WithContext(ctx), WithBufferSize(0),
+			),
+			WithContext(ctx), WithBufferSize(0),
+		)
+		<-output
+		cancel()
+
+		requireNoLeaks(t, baseline)
+	})
+}
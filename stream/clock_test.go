@@ -0,0 +1,116 @@
+package stream
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a manually advanced Clock for deterministic tests of the
+// time-based stages.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	reads   int
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	at      time.Time
+	channel chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(0, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reads++
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	channel := make(chan time.Time, 1)
+	if d <= 0 {
+		channel <- c.now
+		return channel
+	}
+	c.waiters = append(c.waiters, fakeWaiter{c.now.Add(d), channel})
+	return channel
+}
+
+// Advance moves the clock forward, firing every waiter that has come due.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	waiters := c.waiters[:0]
+	for _, waiter := range c.waiters {
+		if waiter.at.After(c.now) {
+			waiters = append(waiters, waiter)
+			continue
+		}
+		waiter.channel <- c.now
+	}
+	c.waiters = waiters
+}
+
+// WaitForTimers blocks until at least count waiters are pending, which lets a
+// test know that a stage has armed its timer before advancing the clock.
+func (c *fakeClock) WaitForTimers(t *testing.T, count int) {
+	t.Helper()
+	c.waitFor(t, "timers were never armed", func() bool {
+		return len(c.waiters) >= count
+	})
+}
+
+// WaitForReads blocks until Now has been called at least count times, which
+// lets a test know that a stage has stamped a value before advancing the clock.
+func (c *fakeClock) WaitForReads(t *testing.T, count int) {
+	t.Helper()
+	c.waitFor(t, "the time was never read", func() bool {
+		return c.reads >= count
+	})
+}
+
+func (c *fakeClock) waitFor(t *testing.T, message string, done func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		c.mu.Lock()
+		ok := done()
+		c.mu.Unlock()
+		if ok {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	require.FailNow(t, message)
+}
+
+func TestFakeClock(t *testing.T) {
+	clock := newFakeClock()
+	start := clock.Now()
+
+	after := clock.After(time.Second)
+	clock.WaitForTimers(t, 1)
+
+	clock.Advance(999 * time.Millisecond)
+	select {
+	case <-after:
+		require.FailNow(t, "fired early")
+	default:
+	}
+
+	clock.Advance(time.Millisecond)
+	require.Equal(t, start.Add(time.Second), <-after)
+	require.Equal(t, start.Add(time.Second), clock.Now())
+}
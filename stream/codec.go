@@ -0,0 +1,27 @@
+package stream
+
+import "fmt"
+
+// LineError is an error found at a line of the data read or written by a codec
+// stage, such as DecodeCSV. the codec stages wrap it in a TraceError, so find
+// it with errors.As.
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (err *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", err.Line, err.Err)
+}
+
+func (err *LineError) Unwrap() error {
+	return err.Err
+}
+
+// lineError wraps err, if any, in a LineError.
+func lineError(line int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &LineError{Line: line, Err: err}
+}
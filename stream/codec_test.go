@@ -0,0 +1,34 @@
+package stream
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// requireLine checks that err holds a LineError at the line, traced by the
+// stage from within the stage's own file.
+func requireLine(t *testing.T, err error, stage string, line int) {
+	t.Helper()
+	var lineErr *LineError
+	require.ErrorAs(t, err, &lineErr)
+	require.Equal(t, line, lineErr.Line)
+
+	var traced *TraceError
+	require.ErrorAs(t, err, &traced)
+	require.Equal(t, stage, traced.Frames[0].Stage)
+	require.NotEqual(t, "codec.go", filepath.Base(traced.Frames[0].File))
+	require.Contains(t, traced.Frames[0].Function, stage)
+}
+
+func TestLineError(t *testing.T) {
+	err := errors.New("generic error")
+
+	require.NoError(t, lineError(3, nil))
+
+	wrapped := lineError(3, err)
+	require.ErrorIs(t, wrapped, err)
+	require.Equal(t, "line 3: generic error", wrapped.Error())
+}
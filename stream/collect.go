@@ -6,6 +6,11 @@ import "context"
 //
 // Similar to Batch, it will build a slice of outputs. Unlike Batch, it is all
 // or nothing. An error will start a background Drain and send an error Result.
+// A cancelled context also drains the input, but sends nothing.
+//
+// WithErrorPolicy relaxes the all or nothing default: SkipErrors collects only
+// the valid values, and CollectErrors sends the valid values together with the
+// joined errors.
 //
 // In the interest of performance,
 // Do not use Collect on unbounded streams.
@@ -13,21 +18,26 @@ func Collect[T any](
 	input <-chan *Result[T],
 	opts ...Option,
 ) <-chan *Result[[]T] {
+	policy := newOptions(opts...).errorPolicy
+
 	return Stream(func(ctx context.Context, output chan<- *Result[[]T]) {
 		var acc []T
+		errs := &errorTolerance{policy: policy}
 		for result := range input {
-			select {
-			case <-ctx.Done():
+			if ctx.Err() != nil {
+				go Drain(input)
 				return
-			default:
-				if result.Error != nil {
-					output <- NewResult[[]T](nil, result.Error)
+			}
+			if result.Error != nil {
+				if !errs.tolerate(result.Error) {
 					go Drain(input)
+					send(ctx, output, NewResult[[]T](nil, result.Error))
 					return
 				}
-				acc = append(acc, result.Value)
+				continue
 			}
+			acc = append(acc, result.Value)
 		}
-		output <- NewResult(acc, nil)
+		send(ctx, output, NewResult(acc, errs.err()))
 	}, opts...)
 }
@@ -61,4 +61,28 @@ func TestCollect(t *testing.T) {
 		require.True(t, ok)
 		require.Equal(t, NewResult[[]int](nil, err), value)
 	})
+
+	t.Run("error policies", func(t *testing.T) {
+		err1, err2 := errors.New("first"), errors.New("second")
+		src := func() <-chan *Result[int] {
+			return Stream(func(_ context.Context, output chan<- *Result[int]) {
+				output <- NewResult(1, nil)
+				output <- NewResult(0, err1)
+				output <- NewResult(2, nil)
+				output <- NewResult(0, err2)
+				output <- NewResult(3, nil)
+			})
+		}
+
+		value := <-Collect(src(), WithErrorPolicy(FailFast))
+		require.Equal(t, NewResult[[]int](nil, err1), value)
+
+		value = <-Collect(src(), WithErrorPolicy(SkipErrors))
+		require.Equal(t, NewResult([]int{1, 2, 3}, nil), value)
+
+		value = <-Collect(src(), WithErrorPolicy(CollectErrors))
+		require.Equal(t, []int{1, 2, 3}, value.Value)
+		require.ErrorIs(t, value.Error, err1)
+		require.ErrorIs(t, value.Error, err2)
+	})
 }
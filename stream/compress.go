@@ -0,0 +1,189 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"io"
+)
+
+// decompressBufferSize is the most decompressed data sent in a single Result.
+const decompressBufferSize = 32 * 1024
+
+// Compression is a compression format from the standard library.
+type Compression int
+
+const (
+	// Gzip is the gzip format of compress/gzip.
+	Gzip Compression = iota
+	// Zlib is the zlib format of compress/zlib.
+	Zlib
+	// Flate is raw DEFLATE data, as compress/flate.
+	Flate
+)
+
+func (format Compression) String() string {
+	switch format {
+	case Gzip:
+		return "gzip"
+	case Zlib:
+		return "zlib"
+	case Flate:
+		return "flate"
+	default:
+		return fmt.Sprintf("Compression(%d)", int(format))
+	}
+}
+
+// writer creates a compressing writer onto w.
+func (format Compression) writer(w io.Writer) (io.WriteCloser, error) {
+	switch format {
+	case Gzip:
+		return gzip.NewWriter(w), nil
+	case Zlib:
+		return zlib.NewWriter(w), nil
+	case Flate:
+		return flate.NewWriter(w, flate.DefaultCompression)
+	default:
+		return nil, fmt.Errorf("stream.Compress - unknown %s", format)
+	}
+}
+
+// reader creates a decompressing reader from r.
+func (format Compression) reader(r io.Reader) (io.Reader, error) {
+	switch format {
+	case Gzip:
+		// multistream is the default, so concatenated members are read as one.
+		return gzip.NewReader(r)
+	case Zlib:
+		return zlib.NewReader(r)
+	case Flate:
+		return flate.NewReader(r), nil
+	default:
+		return nil, fmt.Errorf("stream.Decompress - unknown %s", format)
+	}
+}
+
+// Compress compresses a stream of chunks as it goes, sending the compressed
+// data available after each chunk and the remainder once the input is done.
+// the output chunks together make a single compressed stream.
+//
+// error Results are passed on, leaving a gap in the compressed data.
+func Compress(
+	input <-chan *Result[[]byte],
+	format Compression,
+	opts ...Option,
+) <-chan *Result[[]byte] {
+	return Stream(func(ctx context.Context, output chan<- *Result[[]byte]) {
+		defer func() {
+			go Drain(input)
+		}()
+
+		var buffer bytes.Buffer
+		writer, err := format.writer(&buffer)
+		if err != nil {
+			send(ctx, output, NewResult[[]byte](nil, err))
+			return
+		}
+		// flush sends whatever has been compressed so far.
+		flush := func() bool {
+			if buffer.Len() == 0 {
+				return true
+			}
+			chunk := bytes.Clone(buffer.Bytes())
+			buffer.Reset()
+			return send(ctx, output, NewResult(chunk, nil))
+		}
+
+		for result := range input {
+			if result.Error != nil {
+				if !send(ctx, output, result) {
+					return
+				}
+				continue
+			}
+			if _, err := writer.Write(result.Value); err != nil {
+				send(ctx, output, NewResult[[]byte](nil,
+					fmt.Errorf("stream.Compress %s - %w", format, err)))
+				return
+			}
+			if !flush() {
+				return
+			}
+		}
+
+		if err := writer.Close(); err != nil {
+			send(ctx, output, NewResult[[]byte](nil,
+				fmt.Errorf("stream.Compress %s - %w", format, err)))
+			return
+		}
+		flush()
+	}, opts...)
+}
+
+// Decompress decompresses a stream of chunks as it goes, without holding the
+// whole of the compressed or decompressed data. concatenated gzip members are
+// decompressed as one stream.
+//
+// corrupt or truncated data, or an error Result in the input, ends the stream
+// with an error Result, after the data decompressed before it. an empty input
+// sends nothing.
+func Decompress(
+	input <-chan *Result[[]byte],
+	format Compression,
+	opts ...Option,
+) <-chan *Result[[]byte] {
+	return Stream(func(ctx context.Context, output chan<- *Result[[]byte]) {
+		pipeReader, pipeWriter := io.Pipe()
+		// closing the reader stops the feed, which then drains the input.
+		defer pipeReader.Close()
+
+		go func() {
+			defer Drain(input)
+			for result := range input {
+				if result.Error != nil {
+					pipeWriter.CloseWithError(result.Error)
+					return
+				}
+				if _, err := pipeWriter.Write(result.Value); err != nil {
+					return
+				}
+			}
+			pipeWriter.Close()
+		}()
+
+		fail := func(err error) {
+			send(ctx, output, NewResult[[]byte](nil,
+				fmt.Errorf("stream.Decompress %s - %w", format, err)))
+		}
+
+		buffered := bufio.NewReader(pipeReader)
+		if _, err := buffered.Peek(1); err == io.EOF {
+			return
+		}
+		reader, err := format.reader(buffered)
+		if err != nil {
+			fail(err)
+			return
+		}
+
+		for {
+			chunk := make([]byte, decompressBufferSize)
+			n, err := reader.Read(chunk)
+			if n > 0 && !send(ctx, output, NewResult(chunk[:n], nil)) {
+				return
+			}
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				fail(err)
+				return
+			}
+		}
+	}, opts...)
+}
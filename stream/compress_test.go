@@ -0,0 +1,129 @@
+package stream
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// chunks splits data into a stream of chunks of the size.
+func chunks(data []byte, size int) <-chan *Result[[]byte] {
+	return Stream(func(_ context.Context, output chan<- *Result[[]byte]) {
+		for len(data) > 0 {
+			n := min(size, len(data))
+			output <- NewResult(data[:n], nil)
+			data = data[n:]
+		}
+	})
+}
+
+// joinChunks concatenates a stream of chunks, stopping at the first error.
+func joinChunks(input <-chan *Result[[]byte]) ([]byte, error) {
+	return Fold(input, []byte{},
+		func(_ context.Context, acc []byte, chunk []byte) ([]byte, error) {
+			return append(acc, chunk...), nil
+		})
+}
+
+func TestCompression(t *testing.T) {
+	data := []byte(strings.Repeat("the quick brown fox jumps over the lazy dog\n", 5000))
+
+	for _, format := range []Compression{Gzip, Zlib, Flate} {
+		t.Run(format.String()+" round trip", func(t *testing.T) {
+			compressed, err := joinChunks(Compress(chunks(data, 1000), format))
+			require.NoError(t, err)
+			require.Less(t, len(compressed), len(data))
+
+			decompressed, err := joinChunks(Decompress(chunks(compressed, 100), format))
+			require.NoError(t, err)
+			require.Equal(t, data, decompressed)
+		})
+
+		t.Run(format.String()+" truncated", func(t *testing.T) {
+			compressed, err := joinChunks(Compress(chunks(data, 1000), format))
+			require.NoError(t, err)
+
+			_, err = joinChunks(Decompress(chunks(compressed[:len(compressed)/2], 100), format))
+			require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		})
+
+		t.Run(format.String()+" empty", func(t *testing.T) {
+			validateChannel(t, nil, false, Decompress(chunks(nil, 1), format))
+		})
+	}
+
+	t.Run("matches the gzip package", func(t *testing.T) {
+		compressed, err := joinChunks(Compress(chunks(data, 1000), Gzip))
+		require.NoError(t, err)
+
+		reader, err := gzip.NewReader(bytes.NewReader(compressed))
+		require.NoError(t, err)
+		decompressed, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.Equal(t, data, decompressed)
+	})
+
+	t.Run("multi-member gzip", func(t *testing.T) {
+		first, err := joinChunks(Compress(chunks([]byte("first\n"), 10), Gzip))
+		require.NoError(t, err)
+		second, err := joinChunks(Compress(chunks([]byte("second\n"), 10), Gzip))
+		require.NoError(t, err)
+
+		decompressed, err := joinChunks(Decompress(chunks(append(first, second...), 7), Gzip))
+		require.NoError(t, err)
+		require.Equal(t, "first\nsecond\n", string(decompressed))
+	})
+
+	t.Run("bad file", func(t *testing.T) {
+		file, err := os.ReadFile("testfiles/example_bad.gz")
+		require.NoError(t, err)
+
+		decompressed := Decompress(chunks(file, 4), Gzip)
+		result := <-decompressed
+		require.ErrorIs(t, result.Error, gzip.ErrHeader)
+		require.ErrorContains(t, result.Error, "stream.Decompress gzip")
+		validateChannel(t, nil, false, decompressed)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		err := errors.New("generic error")
+		failing := func() <-chan *Result[[]byte] {
+			return Stream(func(_ context.Context, output chan<- *Result[[]byte]) {
+				output <- NewResult([]byte("abc"), nil)
+				output <- NewResult[[]byte](nil, err)
+			})
+		}
+
+		compressed := Compress(failing(), Gzip)
+		var sawError bool
+		for result := range compressed {
+			sawError = sawError || errors.Is(result.Error, err)
+		}
+		require.True(t, sawError)
+
+		_, decompressErr := joinChunks(Decompress(failing(), Gzip))
+		require.ErrorIs(t, decompressErr, err)
+	})
+
+	t.Run("cancelable", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		compressed, err := joinChunks(Compress(chunks(data, 1000), Gzip))
+		require.NoError(t, err)
+		decompressed := Decompress(chunks(compressed, 10), Gzip,
+			WithContext(ctx), WithBufferSize(0))
+		require.NoError(t, (<-decompressed).Error)
+		cancel()
+
+		requireNoLeaks(t, baseline)
+	})
+}
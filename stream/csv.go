@@ -0,0 +1,261 @@
+package stream
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+// csvField is a struct field mapped to a CSV column.
+type csvField struct {
+	name  string
+	index []int
+}
+
+// csvFields maps the exported fields of a struct type to columns, named by
+// their csv tag or else the field name. a tag of "-" skips the field.
+func csvFields(typ reflect.Type) ([]csvField, error) {
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("stream.csv - %s is not a struct", typ)
+	}
+
+	var fields []csvField
+	for _, field := range reflect.VisibleFields(typ) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("csv"); ok {
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		fields = append(fields, csvField{name, field.Index})
+	}
+	return fields, nil
+}
+
+// DecodeCSV reads a CSV reader with a header row one record at a time, sending
+// each record decoded into a struct T. columns are matched to fields by csv
+// tag or field name, unknown columns are ignored and missing ones are left at
+// their zero value.
+//
+// fields may be strings, bools, numbers or encoding.TextUnmarshalers. a record
+// that fails to parse or decode is sent as an error Result and decoding
+// carries on, while a read error ends the stream. errors hold a LineError,
+// traced by TraceStage, unless a read error has no line.
+func DecodeCSV[T any](r io.Reader, opts ...Option) <-chan *Result[T] {
+	return Stream(func(ctx context.Context, output chan<- *Result[T]) {
+		fields, err := csvFields(reflect.TypeFor[T]())
+		if err != nil {
+			send(ctx, output, NewResult(*new(T), err))
+			return
+		}
+
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		header, err := reader.Read()
+		if err != nil {
+			if err != io.EOF {
+				send(ctx, output, NewResult(TraceStage("DecodeCSV", *new(T), lineError(1, err))))
+			}
+			return
+		}
+		columns := make([]*csvField, len(header))
+		for i, name := range header {
+			for j := range fields {
+				if fields[j].name == name {
+					columns[i] = &fields[j]
+				}
+			}
+		}
+
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				return
+			}
+
+			var value T
+			var line int
+			var parseError *csv.ParseError
+			switch {
+			case err == nil:
+				line, _ = reader.FieldPos(0)
+				err = decodeCSVRecord(reflect.ValueOf(&value).Elem(), columns, record)
+			case errors.As(err, &parseError):
+				line = parseError.StartLine
+			default:
+				// a read error is not recoverable, nor tied to a line.
+				send(ctx, output, NewResult(TraceStage("DecodeCSV", value, err)))
+				return
+			}
+			err = lineError(line, err)
+			if !send(ctx, output, NewResult(TraceStage("DecodeCSV", value, err))) {
+				return
+			}
+		}
+	}, opts...)
+}
+
+// decodeCSVRecord sets the fields of value from the columns of a record.
+func decodeCSVRecord(value reflect.Value, columns []*csvField, record []string) error {
+	for i, text := range record {
+		if i >= len(columns) || columns[i] == nil {
+			continue
+		}
+		field, err := value.FieldByIndexErr(columns[i].index)
+		if err == nil {
+			err = decodeCSVField(field, text)
+		}
+		if err != nil {
+			return fmt.Errorf("stream.DecodeCSV - field %q: %w", columns[i].name, err)
+		}
+	}
+	return nil
+}
+
+// decodeCSVField parses text into a single field.
+func decodeCSVField(field reflect.Value, text string) error {
+	if unmarshaler, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(text))
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(text)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		field.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(text, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(text, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(text, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(parsed)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+// encodeCSVField formats a single field, which must be addressable so that a
+// MarshalText with a pointer receiver is found, as it is when decoding.
+func encodeCSVField(field reflect.Value) (string, error) {
+	if marshaler, ok := field.Addr().Interface().(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		return string(text), err
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		return field.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(field.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(field.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(field.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(field.Float(), 'g', -1, field.Type().Bits()), nil
+	default:
+		return "", fmt.Errorf("unsupported type %s", field.Type())
+	}
+}
+
+// encodeCSVRecord formats the fields of an addressable struct value.
+func encodeCSVRecord(value reflect.Value, fields []csvField) ([]string, error) {
+	record := make([]string, len(fields))
+	for i, field := range fields {
+		fieldValue, err := value.FieldByIndexErr(field.index)
+		if err == nil {
+			record[i], err = encodeCSVField(fieldValue)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("stream.EncodeCSV - field %q: %w", field.name, err)
+		}
+	}
+	return record, nil
+}
+
+// EncodeCSV encodes each struct value as a CSV record, ready for ToWriter. the
+// header row, named as for DecodeCSV, is sent first.
+//
+// error Results are passed on, and a value that fails to encode is sent as an
+// error Result holding a LineError with its output line number, traced by
+// TraceStage.
+func EncodeCSV[T any](input <-chan *Result[T], opts ...Option) <-chan *Result[[]byte] {
+	return Stream(func(ctx context.Context, output chan<- *Result[[]byte]) {
+		defer func() {
+			go Drain(input)
+		}()
+
+		fields, err := csvFields(reflect.TypeFor[T]())
+		if err != nil {
+			send(ctx, output, NewResult[[]byte](nil, err))
+			return
+		}
+
+		// encode formats a single record.
+		encode := func(record []string) []byte {
+			var buffer bytes.Buffer
+			writer := csv.NewWriter(&buffer)
+			_ = writer.Write(record)
+			writer.Flush()
+			return buffer.Bytes()
+		}
+
+		header := make([]string, len(fields))
+		for i, field := range fields {
+			header[i] = field.name
+		}
+		if !send(ctx, output, NewResult(encode(header), nil)) {
+			return
+		}
+
+		// line is where the next record starts, as a quoted field may span
+		// several lines.
+		line := 2
+		for result := range input {
+			next := NewResult[[]byte](nil, result.Error)
+			if result.Error == nil {
+				value := reflect.New(reflect.TypeFor[T]()).Elem()
+				value.Set(reflect.ValueOf(result.Value))
+				record, err := encodeCSVRecord(value, fields)
+				var encoded []byte
+				if err == nil {
+					encoded = encode(record)
+				}
+				next = NewResult(TraceStage("EncodeCSV", encoded, lineError(line, err)))
+				line += bytes.Count(encoded, []byte("\n"))
+			}
+			if !send(ctx, output, next) {
+				return
+			}
+		}
+	}, opts...)
+}
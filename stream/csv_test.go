@@ -0,0 +1,145 @@
+package stream
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeCSV(t *testing.T) {
+	t.Run("decodes by header", func(t *testing.T) {
+		input := "score,extra,name,count\n0.5,x,a,1\n,,\"b\nc\",2\n"
+		values := DecodeCSV[record](strings.NewReader(input))
+		require.Equal(t, NewResult(record{"a", 1, 0.5, ""}, nil), <-values)
+		require.ErrorContains(t, (<-values).Error, `field "score"`)
+		validateChannel(t, nil, false, values)
+	})
+
+	t.Run("line numbers", func(t *testing.T) {
+		input := "name,count\na,1\n\"b\nb\",x\nc,3\nd,\"4\ne,5\n"
+		values := DecodeCSV[record](strings.NewReader(input))
+		require.Equal(t, NewResult(record{Name: "a", Count: 1}, nil), <-values)
+		requireLine(t, (<-values).Error, "DecodeCSV", 3)
+		require.Equal(t, NewResult(record{Name: "c", Count: 3}, nil), <-values)
+		requireLine(t, (<-values).Error, "DecodeCSV", 6)
+		validateChannel(t, nil, false, values)
+	})
+
+	t.Run("text unmarshaler", func(t *testing.T) {
+		type event struct {
+			At   time.Time `csv:"at"`
+			Name string
+		}
+		values := DecodeCSV[event](strings.NewReader("Name,at\nx,1970-01-01T00:00:01Z\n"))
+		require.Equal(t, NewResult(event{time.Unix(1, 0).UTC(), "x"}, nil), <-values)
+		validateChannel(t, nil, false, values)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		validateChannel(t, nil, false, DecodeCSV[record](strings.NewReader("")))
+	})
+
+	t.Run("not a struct", func(t *testing.T) {
+		values := DecodeCSV[int](strings.NewReader("a\n1\n"))
+		require.Error(t, (<-values).Error)
+		validateChannel(t, nil, false, values)
+	})
+}
+
+func TestEncodeCSV(t *testing.T) {
+	err := errors.New("generic error")
+	values := Stream(func(_ context.Context, output chan<- *Result[record]) {
+		output <- NewResult(record{"a", 1, 0.5, "ignored"}, nil)
+		output <- NewResult(record{}, err)
+		output <- NewResult(record{"b,\nc", 2, 0, ""}, nil)
+	})
+
+	lines := EncodeCSV(values)
+	require.Equal(t, NewResult([]byte("name,count,score\n"), nil), <-lines)
+	require.Equal(t, NewResult([]byte("a,1,0.5\n"), nil), <-lines)
+	require.Equal(t, NewResult[[]byte](nil, err), <-lines)
+	require.Equal(t, NewResult([]byte("\"b,\nc\",2,0\n"), nil), <-lines)
+	validateChannel(t, nil, false, lines)
+
+	t.Run("unsupported field", func(t *testing.T) {
+		type bad struct {
+			Name  string
+			Items []int
+		}
+		values := Stream(func(_ context.Context, output chan<- *Result[bad]) {
+			output <- NewResult(bad{"a", nil}, nil)
+		})
+		lines := EncodeCSV(values)
+		<-lines
+		requireLine(t, (<-lines).Error, "EncodeCSV", 2)
+		validateChannel(t, nil, false, lines)
+	})
+}
+
+// level has a text encoding on its pointer receiver.
+type level int
+
+func (l *level) MarshalText() ([]byte, error) {
+	return []byte(strings.Repeat("*", int(*l))), nil
+}
+
+func (l *level) UnmarshalText(text []byte) error {
+	*l = level(len(text))
+	return nil
+}
+
+func TestCSVPointerReceivers(t *testing.T) {
+	type rating struct {
+		Name  string
+		Level level
+	}
+
+	lines := EncodeCSV(FromSlice([]rating{{"a", 3}}))
+	require.Equal(t, NewResult([]byte("Name,Level\n"), nil), <-lines)
+	require.Equal(t, NewResult([]byte("a,***\n"), nil), <-lines)
+	validateChannel(t, nil, false, lines)
+
+	values := DecodeCSV[rating](strings.NewReader("Name,Level\na,***\n"))
+	require.Equal(t, NewResult(rating{"a", 3}, nil), <-values)
+	validateChannel(t, nil, false, values)
+}
+
+func TestCSVNilEmbedded(t *testing.T) {
+	type Extra struct{ Note string }
+	type row struct {
+		Name string
+		*Extra
+	}
+
+	lines := EncodeCSV(FromSlice([]row{{"a", &Extra{"x"}}, {"b", nil}}))
+	require.Equal(t, NewResult([]byte("Name,Note\n"), nil), <-lines)
+	require.Equal(t, NewResult([]byte("a,x\n"), nil), <-lines)
+	err := (<-lines).Error
+	requireLine(t, err, "EncodeCSV", 3)
+	require.ErrorContains(t, err, `field "Note"`)
+	validateChannel(t, nil, false, lines)
+
+	values := DecodeCSV[row](strings.NewReader("Name,Note\nb,x\n"))
+	require.ErrorContains(t, (<-values).Error, `field "Note"`)
+	validateChannel(t, nil, false, values)
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	records := []record{{"a", 1, 0.5, ""}, {"b\"quoted\"", 2, 1.5, ""}}
+
+	var buffer bytes.Buffer
+	require.NoError(t, ToWriter(EncodeCSV(FromSlice(records)), &buffer,
+		func(line []byte) ([]byte, error) { return line, nil }))
+
+	decoded, err := Fold(DecodeCSV[record](&buffer), nil,
+		func(_ context.Context, acc []record, value record) ([]record, error) {
+			return append(acc, value), nil
+		})
+	require.NoError(t, err)
+	require.Equal(t, records, decoded)
+}
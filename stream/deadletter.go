@@ -0,0 +1,149 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// DeadLetter is an input value that failed to be processed, with the reason.
+type DeadLetter[T any] struct {
+	Value T
+	Error error
+}
+
+// deadLetterJSON is the JSON Lines form of a DeadLetter. the error can only be
+// kept as its message.
+type deadLetterJSON[T any] struct {
+	Value T      `json:"value"`
+	Error string `json:"error"`
+}
+
+func (letter DeadLetter[T]) MarshalJSON() ([]byte, error) {
+	var message string
+	if letter.Error != nil {
+		message = letter.Error.Error()
+	}
+	return json.Marshal(deadLetterJSON[T]{letter.Value, message})
+}
+
+func (letter *DeadLetter[T]) UnmarshalJSON(data []byte) error {
+	var decoded deadLetterJSON[T]
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	letter.Value = decoded.Value
+	letter.Error = errors.New(decoded.Error)
+	return nil
+}
+
+// DeadLetterSink receives the inputs that a Transform function failed on. see
+// WithDeadLetters.
+type DeadLetterSink interface {
+	WriteDeadLetter(value any, err error) error
+}
+
+// WithDeadLetters sends a copy of every input that fails in a Transform-family
+// stage, along with its error, to the sink. the error Result is still sent
+// downstream, joined with any error from the sink itself.
+func WithDeadLetters(sink DeadLetterSink) Option {
+	return func(opts *options) {
+		opts.deadLetters = sink
+	}
+}
+
+// deadLetter records a failed input in the sink, if there is one, and returns
+// the error to forward.
+func deadLetter(sink DeadLetterSink, value any, err error) error {
+	if err == nil || sink == nil {
+		return err
+	}
+	if sinkErr := sink.WriteDeadLetter(value, err); sinkErr != nil {
+		return errors.Join(err, sinkErr)
+	}
+	return err
+}
+
+// MemoryDeadLetters is a DeadLetterSink that keeps the dead letters in memory.
+// it is safe for concurrent use.
+type MemoryDeadLetters[T any] struct {
+	mu      sync.Mutex
+	letters []DeadLetter[T]
+}
+
+func (sink *MemoryDeadLetters[T]) WriteDeadLetter(value any, err error) error {
+	typed, ok := value.(T)
+	if !ok {
+		return fmt.Errorf("stream: dead letter of type %T, expected %T",
+			value, *new(T))
+	}
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	sink.letters = append(sink.letters, DeadLetter[T]{typed, err})
+	return nil
+}
+
+// Letters returns a copy of the dead letters received so far.
+func (sink *MemoryDeadLetters[T]) Letters() []DeadLetter[T] {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	return append([]DeadLetter[T](nil), sink.letters...)
+}
+
+// JSONLinesDeadLetters is a DeadLetterSink that writes each dead letter as a
+// line of JSON, such as to a file. it is safe for concurrent use. the values
+// must be JSON encodable, and errors are kept as their messages.
+type JSONLinesDeadLetters struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewJSONLinesDeadLetters creates a JSONLinesDeadLetters writing to w.
+func NewJSONLinesDeadLetters(w io.Writer) *JSONLinesDeadLetters {
+	return &JSONLinesDeadLetters{encoder: json.NewEncoder(w)}
+}
+
+func (sink *JSONLinesDeadLetters) WriteDeadLetter(value any, err error) error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	return sink.encoder.Encode(DeadLetter[any]{value, err})
+}
+
+// Replay re-feeds the values of dead letters into a pipeline, such as after a
+// fix is deployed.
+func Replay[T any](letters []DeadLetter[T], opts ...Option) <-chan *Result[T] {
+	return Stream(func(ctx context.Context, output chan<- *Result[T]) {
+		for _, letter := range letters {
+			if !send(ctx, output, NewResult(letter.Value, nil)) {
+				return
+			}
+		}
+	}, opts...)
+}
+
+// ReplayJSONLines is a Replay of the dead letters written by a
+// JSONLinesDeadLetters. a line that can't be decoded is sent as an error
+// Result and ends the replay.
+func ReplayJSONLines[T any](r io.Reader, opts ...Option) <-chan *Result[T] {
+	return Stream(func(ctx context.Context, output chan<- *Result[T]) {
+		decoder := json.NewDecoder(r)
+		for {
+			var letter DeadLetter[T]
+			err := decoder.Decode(&letter)
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				send(ctx, output, NewResult(*new(T), err))
+				return
+			}
+			if !send(ctx, output, NewResult(letter.Value, nil)) {
+				return
+			}
+		}
+	}, opts...)
+}
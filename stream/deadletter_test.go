@@ -0,0 +1,110 @@
+package stream
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadLetters(t *testing.T) {
+	errOdd := errors.New("odd")
+	evens := func(_ context.Context, input int) (int, error) {
+		if input%2 == 1 {
+			return 0, errOdd
+		}
+		return input, nil
+	}
+	src := func() <-chan *Result[int] {
+		return Stream(func(_ context.Context, output chan<- *Result[int]) {
+			for i := range 6 {
+				output <- NewResult(i, nil)
+			}
+		})
+	}
+
+	t.Run("memory", func(t *testing.T) {
+		sink := &MemoryDeadLetters[int]{}
+		values, errs := SplitErrors(Transform(src(), evens, WithDeadLetters(sink)))
+
+		actual := []int{}
+		for value := range values {
+			actual = append(actual, value)
+		}
+		require.Equal(t, []int{0, 2, 4}, actual)
+		for err := range errs {
+			require.Equal(t, errOdd, err)
+		}
+		require.Equal(t, []DeadLetter[int]{
+			{1, errOdd}, {3, errOdd}, {5, errOdd},
+		}, sink.Letters())
+	})
+
+	t.Run("parallel", func(t *testing.T) {
+		sink := &MemoryDeadLetters[int]{}
+		Drain(ParallelTransform(src(), 3, evens, WithDeadLetters(sink)))
+
+		require.ElementsMatch(t, []DeadLetter[int]{
+			{1, errOdd}, {3, errOdd}, {5, errOdd},
+		}, sink.Letters())
+	})
+
+	t.Run("wrong type", func(t *testing.T) {
+		sink := &MemoryDeadLetters[string]{}
+		result := <-Transform(
+			Stream(func(_ context.Context, output chan<- *Result[int]) {
+				output <- NewResult(1, nil)
+			}),
+			evens,
+			WithDeadLetters(sink),
+		)
+
+		require.ErrorIs(t, result.Error, errOdd)
+		require.ErrorContains(t, result.Error, "dead letter of type int")
+		require.Empty(t, sink.Letters())
+	})
+
+	t.Run("json lines replay", func(t *testing.T) {
+		var file bytes.Buffer
+		sink := NewJSONLinesDeadLetters(&file)
+		Drain(Transform(src(), evens, WithDeadLetters(sink)))
+
+		require.Equal(t,
+			`{"value":1,"error":"odd"}`+"\n"+
+				`{"value":3,"error":"odd"}`+"\n"+
+				`{"value":5,"error":"odd"}`+"\n",
+			file.String(),
+		)
+
+		// after the fix, the replayed values succeed.
+		fixed := Transform(ReplayJSONLines[int](&file),
+			func(_ context.Context, input int) (int, error) {
+				return input * 10, nil
+			})
+		require.Equal(t, NewResult(10, nil), <-fixed)
+		require.Equal(t, NewResult(30, nil), <-fixed)
+		require.Equal(t, NewResult(50, nil), <-fixed)
+		validateChannel(t, nil, false, fixed)
+	})
+
+	t.Run("json lines corrupt", func(t *testing.T) {
+		replayed := ReplayJSONLines[int](strings.NewReader(
+			`{"value":1,"error":"odd"}` + "\n" + `{"value":"x"}` + "\n",
+		))
+
+		require.Equal(t, NewResult(1, nil), <-replayed)
+		require.Error(t, (<-replayed).Error)
+		validateChannel(t, nil, false, replayed)
+	})
+
+	t.Run("memory replay", func(t *testing.T) {
+		replayed := Replay([]DeadLetter[int]{{1, errOdd}, {3, errOdd}})
+
+		require.Equal(t, NewResult(1, nil), <-replayed)
+		require.Equal(t, NewResult(3, nil), <-replayed)
+		validateChannel(t, nil, false, replayed)
+	})
+}
@@ -1,9 +1,15 @@
 package stream
 
-import "context"
+import (
+	"context"
+	"hash/fnv"
+)
 
 // Distribute standardizes the fan-out case, where a single producer generates
 // data to be handled by channelCount concurrent downstream processes.
+//
+// If the context is cancelled, every output is closed and the input is drained
+// in the background.
 func Distribute[T any](
 	input <-chan T,
 	channelCount int,
@@ -14,10 +20,9 @@ func Distribute[T any](
 	for i := range channelCount {
 		outputs[i] = Stream(func(ctx context.Context, output chan<- T) {
 			for value := range input {
-				select {
-				case <-ctx.Done():
+				if !send(ctx, output, value) {
+					go Drain(input)
 					return
-				case output <- value:
 				}
 			}
 		}, opts...)
@@ -25,3 +30,64 @@ func Distribute[T any](
 
 	return outputs
 }
+
+// DistributeByKey is a Distribute that partitions the input by key. the key of
+// each value is hashed to pick its output channel, so every value with the same
+// key is handled by the same downstream process, in order.
+//
+// this enables per-key stateful work in a Processor. note that a slow output
+// blocks the others, since values must be routed in order.
+//
+// with no channels there is nowhere to route to, so no outputs are returned and
+// the input is drained in the background.
+//
+// on a channel of Results, a panic in key is sent to the first output as an
+// error Result holding a PanicError. any other channel has nowhere to report
+// it, so the panic continues, as it does in Stream.
+func DistributeByKey[T any](
+	input <-chan T,
+	channelCount int,
+	key func(value T) string,
+	opts ...Option,
+) []<-chan T {
+	if channelCount <= 0 {
+		go Drain(input)
+		return []<-chan T{}
+	}
+	options := newOptions(opts...)
+
+	channels := make([]chan T, channelCount)
+	outputs := make([]<-chan T, channelCount)
+	for i := range channelCount {
+		channels[i] = make(chan T, options.bufferSize)
+		outputs[i] = channels[i]
+	}
+
+	resulter, reportable := any(*new(T)).(errorResulter)
+	go func() {
+		defer func() {
+			for _, channel := range channels {
+				close(channel)
+			}
+		}()
+		for value := range input {
+			k, err := protect(options.recoverPanics && reportable, func() (string, error) {
+				return key(value), nil
+			})
+			target := channels[0]
+			if err != nil {
+				value = resulter.errorResult(err).(T)
+			} else {
+				hash := fnv.New32a()
+				hash.Write([]byte(k))
+				target = channels[hash.Sum32()%uint32(channelCount)]
+			}
+			if !send(options.ctx, target, value) {
+				go Drain(input)
+				return
+			}
+		}
+	}()
+
+	return outputs
+}
@@ -2,6 +2,8 @@ package stream
 
 import (
 	"context"
+	"runtime"
+	"strconv"
 	"testing"
 	"time"
 
@@ -58,3 +60,73 @@ func TestDistribute(t *testing.T) {
 		require.ElementsMatch(t, []int{0, 1, 2}, actual)
 	})
 }
+
+func TestDistributeByKey(t *testing.T) {
+	type event struct {
+		customer string
+		sequence int
+	}
+
+	t.Run("partitioned", func(t *testing.T) {
+		customers := []string{"a", "b", "c", "d", "e", "f", "g"}
+		channels := DistributeByKey(
+			Stream(func(_ context.Context, output chan<- event) {
+				for sequence := range 10 {
+					for _, customer := range customers {
+						output <- event{customer, sequence}
+					}
+				}
+			}),
+			3,
+			func(value event) string {
+				return value.customer
+			},
+		)
+
+		// every customer is seen by exactly one channel, in sequence. the
+		// default buffer holds every event, so the channels can be read in turn.
+		owners := map[string]int{}
+		next := map[string]int{}
+		for id, channel := range channels {
+			for value := range channel {
+				if owner, ok := owners[value.customer]; ok {
+					require.Equal(t, owner, id)
+				}
+				owners[value.customer] = id
+				require.Equal(t, next[value.customer], value.sequence)
+				next[value.customer]++
+			}
+		}
+		require.Len(t, next, len(customers))
+	})
+
+	t.Run("cancelable", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		channels := DistributeByKey(counter(1000), 2,
+			func(value *Result[int]) string {
+				return strconv.Itoa(value.Value)
+			},
+			WithContext(ctx), WithBufferSize(0))
+		select {
+		case <-channels[0]:
+		case <-channels[1]:
+		}
+		cancel()
+
+		requireNoLeaks(t, baseline)
+	})
+
+	t.Run("no channels", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+
+		channels := DistributeByKey(counter(100), 0,
+			func(value *Result[int]) string {
+				return strconv.Itoa(value.Value)
+			})
+		require.Empty(t, channels)
+
+		requireNoLeaks(t, baseline)
+	})
+}
@@ -1,7 +1,19 @@
 package stream
 
+import "context"
+
 // Drain is a convenience function to remove all messages from a channel.
 func Drain[T any](input <-chan T) {
 	for range input {
 	}
 }
+
+// stopInput abandons an input early. cancel, which may be nil, should cancel
+// the context of the pipeline feeding input, so an unbounded source stops
+// rather than being drained forever.
+func stopInput[T any](input <-chan T, cancel context.CancelFunc) {
+	if cancel != nil {
+		cancel()
+	}
+	go Drain(input)
+}
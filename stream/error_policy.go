@@ -0,0 +1,88 @@
+package stream
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrorPolicy selects how Collect, Fold and the Batch stages handle errors. see
+// WithErrorPolicy.
+type ErrorPolicy int
+
+const (
+	// FailFast stops at the first error. it is the default for Collect and
+	// Fold, while the Batch stages forward errors inline by default.
+	FailFast ErrorPolicy = iota + 1
+	// SkipErrors discards errors and carries on with the valid values.
+	SkipErrors
+	// CollectErrors carries on with the valid values and reports every error,
+	// joined, once the input is exhausted.
+	CollectErrors
+)
+
+// WithErrorPolicy sets the ErrorPolicy of Collect, Fold and the Batch stages.
+func WithErrorPolicy(policy ErrorPolicy) Option {
+	return func(opts *options) {
+		opts.errorPolicy = policy
+	}
+}
+
+// errorHandler applies an ErrorPolicy to the error Results of a stage that
+// forwards errors inline by default.
+type errorHandler[T any] struct {
+	ctx    context.Context
+	output chan<- *Result[T]
+	policy ErrorPolicy
+	errs   []error
+}
+
+// handle applies the policy to an error Result and reports whether the stage
+// may continue.
+func (handler *errorHandler[T]) handle(result *Result[T]) bool {
+	switch handler.policy {
+	case FailFast:
+		send(handler.ctx, handler.output, result)
+		return false
+	case SkipErrors:
+		return true
+	case CollectErrors:
+		handler.errs = append(handler.errs, result.Error)
+		return true
+	default:
+		return send(handler.ctx, handler.output, result)
+	}
+}
+
+// flush sends the collected errors, if any, as a single error Result.
+func (handler *errorHandler[T]) flush() {
+	if len(handler.errs) > 0 {
+		send(handler.ctx, handler.output,
+			NewResult(*new(T), errors.Join(handler.errs...)))
+	}
+}
+
+// errorTolerance applies an ErrorPolicy to the errors of a stage that stops at
+// the first error by default, such as Collect and Fold.
+type errorTolerance struct {
+	policy ErrorPolicy
+	errs   []error
+}
+
+// tolerate applies the policy to an error and reports whether the stage may
+// continue.
+func (tolerance *errorTolerance) tolerate(err error) bool {
+	switch tolerance.policy {
+	case SkipErrors:
+		return true
+	case CollectErrors:
+		tolerance.errs = append(tolerance.errs, err)
+		return true
+	default:
+		return false
+	}
+}
+
+// err returns the collected errors, joined.
+func (tolerance *errorTolerance) err() error {
+	return errors.Join(tolerance.errs...)
+}
@@ -0,0 +1,165 @@
+package stream
+
+import "context"
+
+// Filter passes on the values that satisfy keep and drops the rest. error
+// Results are always passed on.
+func Filter[T any](
+	input <-chan *Result[T],
+	keep func(value T) bool,
+	opts ...Option,
+) <-chan *Result[T] {
+	return FilterMap(input, func(value T) (T, bool) {
+		return value, keep(value)
+	}, opts...)
+}
+
+// FilterMap is a Filter and a Transform in one. fn returns the new value and
+// whether to keep it. error Results are always passed on.
+//
+// a panic in fn, or in the predicate of any of the stages built on it, is sent
+// as an error Result holding a PanicError, as with Transform.
+func FilterMap[T, U any](
+	input <-chan *Result[T],
+	fn func(value T) (U, bool),
+	opts ...Option,
+) <-chan *Result[U] {
+	options := newOptions(opts...)
+
+	return Stream(func(ctx context.Context, output chan<- *Result[U]) {
+		for result := range input {
+			if result.Error != nil {
+				if !send(ctx, output, NewResult(*new(U), result.Error)) {
+					go Drain(input)
+					return
+				}
+				continue
+			}
+
+			var keep bool
+			value, err := protect(options.recoverPanics, func() (U, error) {
+				value, ok := fn(result.Value)
+				keep = ok
+				return value, nil
+			})
+			next := NewResult(value, err)
+			if err == nil && !keep {
+				continue
+			}
+			if !send(ctx, output, next) {
+				go Drain(input)
+				return
+			}
+		}
+	}, opts...)
+}
+
+// Take passes on the first n values, then closes its output and drains the
+// input in the background. error Results are passed on but not counted.
+//
+// cancel, which may be nil, should cancel the context of the pipeline feeding
+// input. it is called once Take is done, which stops an unbounded source such
+// as Repeat that would otherwise be drained forever.
+func Take[T any](
+	input <-chan *Result[T],
+	n int,
+	cancel context.CancelFunc,
+	opts ...Option,
+) <-chan *Result[T] {
+	return Stream(func(ctx context.Context, output chan<- *Result[T]) {
+		defer stopInput(input, cancel)
+
+		// stop as soon as the last value is sent, rather than waiting on the
+		// next one.
+		for taken := 0; taken < n; {
+			result, ok := <-input
+			if !ok || !send(ctx, output, result) {
+				return
+			}
+			if result.Error == nil {
+				taken++
+			}
+		}
+	}, opts...)
+}
+
+// TakeWhile passes on values until one fails the predicate, then closes its
+// output and drains the input in the background. error Results are passed on.
+// cancel is called once TakeWhile is done, as with Take.
+func TakeWhile[T any](
+	input <-chan *Result[T],
+	predicate func(value T) bool,
+	cancel context.CancelFunc,
+	opts ...Option,
+) <-chan *Result[T] {
+	return Stream(func(ctx context.Context, output chan<- *Result[T]) {
+		defer stopInput(input, cancel)
+
+		for result := range input {
+			if result.Error == nil && !predicate(result.Value) {
+				return
+			}
+			if !send(ctx, output, result) {
+				return
+			}
+		}
+	}, opts...)
+}
+
+// Skip drops the first n values and passes on the rest. error Results are
+// passed on but not counted.
+func Skip[T any](
+	input <-chan *Result[T],
+	n int,
+	opts ...Option,
+) <-chan *Result[T] {
+	skipped := 0
+	return SkipWhile(input, func(T) bool {
+		skipped++
+		return skipped <= n
+	}, opts...)
+}
+
+// SkipWhile drops values until one fails the predicate and passes on the rest.
+// error Results are passed on.
+func SkipWhile[T any](
+	input <-chan *Result[T],
+	predicate func(value T) bool,
+	opts ...Option,
+) <-chan *Result[T] {
+	skipping := true
+	return Filter(input, func(value T) bool {
+		skipping = skipping && predicate(value)
+		return !skipping
+	}, opts...)
+}
+
+// Distinct drops values that have already been seen.
+//
+// note: every distinct value is remembered, so memory grows with the number of
+// distinct values.
+func Distinct[T comparable](
+	input <-chan *Result[T],
+	opts ...Option,
+) <-chan *Result[T] {
+	return DistinctBy(input, func(value T) T {
+		return value
+	}, opts...)
+}
+
+// DistinctBy drops values whose key has already been seen. see Distinct.
+func DistinctBy[T any, K comparable](
+	input <-chan *Result[T],
+	key func(value T) K,
+	opts ...Option,
+) <-chan *Result[T] {
+	seen := map[K]struct{}{}
+	return Filter(input, func(value T) bool {
+		k := key(value)
+		if _, exists := seen[k]; exists {
+			return false
+		}
+		seen[k] = struct{}{}
+		return true
+	}, opts...)
+}
@@ -0,0 +1,160 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter(t *testing.T) {
+	err := errors.New("generic error")
+	// numbers sends the values with an error after the second.
+	numbers := func(values ...int) <-chan *Result[int] {
+		return Stream(func(_ context.Context, output chan<- *Result[int]) {
+			for i, value := range values {
+				output <- NewResult(value, nil)
+				if i == 1 {
+					output <- NewResult(0, err)
+				}
+			}
+		})
+	}
+	collect := func(input <-chan *Result[int]) []*Result[int] {
+		results := []*Result[int]{}
+		for result := range input {
+			results = append(results, result)
+		}
+		return results
+	}
+	even := func(value int) bool {
+		return value%2 == 0
+	}
+
+	t.Run("Filter", func(t *testing.T) {
+		require.Equal(t, []*Result[int]{
+			NewResult(0, err),
+			NewResult(2, nil),
+			NewResult(4, nil),
+		}, collect(Filter(numbers(1, 3, 2, 5, 4), even)))
+	})
+
+	t.Run("FilterMap", func(t *testing.T) {
+		halves := FilterMap(numbers(2, 3, 4),
+			func(value int) (string, bool) {
+				return string(rune('a' + value/2)), even(value)
+			})
+
+		require.Equal(t, NewResult("b", nil), <-halves)
+		require.Equal(t, NewResult("", err), <-halves)
+		require.Equal(t, NewResult("c", nil), <-halves)
+		validateChannel(t, nil, false, halves)
+	})
+
+	t.Run("Take", func(t *testing.T) {
+		require.Equal(t, []*Result[int]{
+			NewResult(1, nil),
+			NewResult(2, nil),
+			NewResult(0, err),
+			NewResult(3, nil),
+		}, collect(Take(numbers(1, 2, 3, 4, 5), 3, nil)))
+	})
+
+	t.Run("TakeWhile", func(t *testing.T) {
+		require.Equal(t, []*Result[int]{
+			NewResult(2, nil),
+			NewResult(4, nil),
+			NewResult(0, err),
+		}, collect(TakeWhile(numbers(2, 4, 5, 6), even, nil)))
+	})
+
+	t.Run("Skip", func(t *testing.T) {
+		require.Equal(t, []*Result[int]{
+			NewResult(0, err),
+			NewResult(3, nil),
+			NewResult(4, nil),
+		}, collect(Skip(numbers(1, 2, 3, 4), 2)))
+	})
+
+	t.Run("SkipWhile", func(t *testing.T) {
+		require.Equal(t, []*Result[int]{
+			NewResult(0, err),
+			NewResult(5, nil),
+			NewResult(6, nil),
+		}, collect(SkipWhile(numbers(2, 4, 5, 6), even)))
+	})
+
+	t.Run("Distinct", func(t *testing.T) {
+		require.Equal(t, []*Result[int]{
+			NewResult(1, nil),
+			NewResult(0, err),
+			NewResult(2, nil),
+		}, collect(Distinct(numbers(1, 1, 2, 1, 2))))
+	})
+
+	t.Run("DistinctBy", func(t *testing.T) {
+		require.Equal(t, []*Result[int]{
+			NewResult(1, nil),
+			NewResult(2, nil),
+			NewResult(0, err),
+		}, collect(DistinctBy(numbers(1, 2, 3, 4), even)))
+	})
+
+	t.Run("Take drains upstream", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+
+		taken := Take(counter(1000), 2, nil, WithBufferSize(0))
+		require.Equal(t, NewResult(0, nil), <-taken)
+		require.Equal(t, NewResult(1, nil), <-taken)
+		validateChannel(t, nil, false, taken)
+
+		requireNoLeaks(t, baseline)
+	})
+
+	t.Run("Take cancels an unbounded source", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		taken := Take(Repeat(7, WithContext(ctx)), 2, cancel)
+		require.Equal(t, NewResult(7, nil), <-taken)
+		require.Equal(t, NewResult(7, nil), <-taken)
+		validateChannel(t, nil, false, taken)
+		require.Error(t, ctx.Err())
+
+		requireNoLeaks(t, baseline)
+	})
+
+	t.Run("TakeWhile cancels an unbounded source", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		i := 0
+		numbers := Generate(func(context.Context) (int, error) {
+			i++
+			return i, nil
+		}, WithContext(ctx))
+		taken := TakeWhile(numbers, func(value int) bool {
+			return value < 3
+		}, cancel)
+		require.Equal(t, NewResult(1, nil), <-taken)
+		require.Equal(t, NewResult(2, nil), <-taken)
+		validateChannel(t, nil, false, taken)
+		require.Error(t, ctx.Err())
+
+		requireNoLeaks(t, baseline)
+	})
+
+	t.Run("cancelable", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		filtered := Filter(counter(1000), even,
+			WithContext(ctx), WithBufferSize(0))
+		require.Equal(t, NewResult(0, nil), <-filtered)
+		cancel()
+
+		requireNoLeaks(t, baseline)
+	})
+}
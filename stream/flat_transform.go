@@ -0,0 +1,74 @@
+package stream
+
+import (
+	"context"
+	"iter"
+)
+
+// FlatTransform is a one-to-many Transform. fn is called with each value and an
+// emit callback that sends zero or more Results downstream, so nothing has to
+// be gathered into a slice first.
+//
+// each emit waits for the consumer, providing backpressure, and reports whether
+// fn should carry on. it returns false once the context is cancelled.
+//
+// WithDeadLetters records the input once for every error emitted, and a panic
+// in fn is sent as an error Result holding a PanicError, as with Transform.
+func FlatTransform[T, U any](
+	input <-chan *Result[T],
+	fn func(ctx context.Context, input T, emit func(value U, err error) bool),
+	opts ...Option,
+) <-chan *Result[U] {
+	options := newOptions(opts...)
+
+	return Stream(func(ctx context.Context, output chan<- *Result[U]) {
+		for result := range input {
+			if result.Error != nil {
+				if !send(ctx, output, NewResult(*new(U), result.Error)) {
+					go Drain(input)
+					return
+				}
+				continue
+			}
+
+			open := true
+			emit := func(value U, err error) bool {
+				if open {
+					err = deadLetter(options.deadLetters, result.Value, err)
+					open = send(ctx, output, NewResult(value, err))
+				}
+				return open
+			}
+			_, err := protect(options.recoverPanics, func() (struct{}, error) {
+				fn(ctx, result.Value, emit)
+				return struct{}{}, nil
+			})
+			if err != nil {
+				emit(*new(U), err)
+			}
+			if !open {
+				go Drain(input)
+				return
+			}
+		}
+	}, opts...)
+}
+
+// FlatTransformSeq is a FlatTransform where fn returns a sequence of values and
+// errors. the sequence is abandoned if the context is cancelled.
+func FlatTransformSeq[T, U any](
+	input <-chan *Result[T],
+	fn func(ctx context.Context, input T) iter.Seq2[U, error],
+	opts ...Option,
+) <-chan *Result[U] {
+	return FlatTransform(input,
+		func(ctx context.Context, input T, emit func(U, error) bool) {
+			for value, err := range fn(ctx, input) {
+				if !emit(value, err) {
+					return
+				}
+			}
+		},
+		opts...,
+	)
+}
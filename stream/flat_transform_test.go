@@ -0,0 +1,109 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlatTransform(t *testing.T) {
+	errEmpty := errors.New("empty")
+	sentences := func() <-chan *Result[string] {
+		return Stream(func(_ context.Context, output chan<- *Result[string]) {
+			output <- NewResult("a b", nil)
+			output <- NewResult("", nil)
+			output <- NewResult("c", nil)
+		})
+	}
+
+	t.Run("callback", func(t *testing.T) {
+		words := FlatTransform(sentences(),
+			func(_ context.Context, input string, emit func(string, error) bool) {
+				if input == "" {
+					emit("", errEmpty)
+					return
+				}
+				for _, word := range strings.Fields(input) {
+					if !emit(word, nil) {
+						return
+					}
+				}
+			})
+
+		require.Equal(t, NewResult("a", nil), <-words)
+		require.Equal(t, NewResult("b", nil), <-words)
+		require.Equal(t, NewResult("", errEmpty), <-words)
+		require.Equal(t, NewResult("c", nil), <-words)
+		validateChannel(t, nil, false, words)
+	})
+
+	t.Run("sequence", func(t *testing.T) {
+		words := FlatTransformSeq(sentences(),
+			func(_ context.Context, input string) iter.Seq2[string, error] {
+				return func(yield func(string, error) bool) {
+					for _, word := range strings.Fields(input) {
+						if !yield(word, nil) {
+							return
+						}
+					}
+				}
+			})
+
+		require.Equal(t, NewResult("a", nil), <-words)
+		require.Equal(t, NewResult("b", nil), <-words)
+		require.Equal(t, NewResult("c", nil), <-words)
+		validateChannel(t, nil, false, words)
+	})
+
+	t.Run("error and panic", func(t *testing.T) {
+		err := errors.New("generic error")
+		src := Stream(func(_ context.Context, output chan<- *Result[int]) {
+			output <- NewResult(0, err)
+			output <- NewResult(1, nil)
+			output <- NewResult(2, nil)
+		})
+		sink := &MemoryDeadLetters[int]{}
+
+		output := FlatTransform(src,
+			func(_ context.Context, input int, emit func(int, error) bool) {
+				emit(input, nil)
+				if input == 1 {
+					panic("boom")
+				}
+			},
+			WithDeadLetters(sink),
+		)
+
+		require.Equal(t, NewResult(0, err), <-output)
+		require.Equal(t, NewResult(1, nil), <-output)
+		require.ErrorAs(t, (<-output).Error, new(*PanicError))
+		require.Equal(t, NewResult(2, nil), <-output)
+		validateChannel(t, nil, false, output)
+		require.Len(t, sink.Letters(), 1)
+		require.Equal(t, 1, sink.Letters()[0].Value)
+	})
+
+	t.Run("cancelable", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		// every input emits without end, until emit says to stop.
+		output := FlatTransform(counter(1000),
+			func(_ context.Context, input int, emit func(int, error) bool) {
+				for emit(input, nil) {
+				}
+			},
+			WithContext(ctx), WithBufferSize(0),
+		)
+		require.Equal(t, NewResult(0, nil), <-output)
+		require.Equal(t, NewResult(0, nil), <-output)
+		cancel()
+
+		requireNoLeaks(t, baseline)
+	})
+}
@@ -9,36 +9,63 @@ import "context"
 // The accumulated value, or an error passed through the stream, will be
 // returned when complete.
 //
-// note: only the WithContext option has any effect.
+// WithErrorPolicy applies to errors from both the stream and the aggregator.
+// SkipErrors and CollectErrors leave the accumulator as it was before the
+// failed value, and CollectErrors returns the joined errors at the end.
+//
+// a panic in the aggregator is handled as an error holding a PanicError.
+//
+// note: only the WithContext, WithErrorPolicy and WithPanicRecovery options
+// have any effect. a cancelled context stops the fold, even while it waits on
+// an idle input, and returns the context's error.
 func Fold[T, U any](
 	input <-chan *Result[T],
 	initialValue U,
 	aggregator func(ctx context.Context, accumulator U, value T) (U, error),
 	opts ...Option,
 ) (U, error) {
-	options := &options{
-		ctx: context.Background(),
-	}
-	for _, opt := range opts {
-		opt(options)
-	}
+	options := newOptions(opts...)
 
 	// a successful process will simply have nothing to drain.
 	defer func() {
 		go Drain(input)
 	}()
 
+	errs := &errorTolerance{policy: options.errorPolicy}
+
 	accumulator := initialValue
-	var err error
-	for result := range input {
+	for {
+		var result *Result[T]
+		var ok bool
+		select {
+		case <-options.ctx.Done():
+			return accumulator, options.ctx.Err()
+		case result, ok = <-input:
+		}
+		if !ok {
+			break
+		}
+		// a value and the cancellation may arrive together.
+		if options.ctx.Err() != nil {
+			return accumulator, options.ctx.Err()
+		}
 		if result.Error != nil {
-			return accumulator, result.Error
+			if !errs.tolerate(result.Error) {
+				return accumulator, result.Error
+			}
+			continue
 		}
-		accumulator, err = aggregator(options.ctx, accumulator, result.Value)
+		next, err := protect(options.recoverPanics, func() (U, error) {
+			return aggregator(options.ctx, accumulator, result.Value)
+		})
 		if err != nil {
-			return accumulator, err
+			if !errs.tolerate(err) {
+				return next, err
+			}
+			continue
 		}
+		accumulator = next
 	}
 
-	return accumulator, nil
+	return accumulator, errs.err()
 }
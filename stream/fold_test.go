@@ -176,4 +176,51 @@ func TestFold(t *testing.T) {
 		require.Equal(t, "timeout", err.Error())
 		require.Equal(t, "", total.String())
 	})
+
+	t.Run("context canceled while idle", func(t *testing.T) {
+		idle := make(chan *Result[int])
+		defer close(idle)
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(time.Millisecond, cancel)
+
+		total, err := Fold(idle, 7,
+			func(_ context.Context, total int, value int) (int, error) {
+				return total + value, nil
+			},
+			WithContext(ctx),
+		)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Equal(t, 7, total)
+	})
+
+	t.Run("error policies", func(t *testing.T) {
+		errStream, errOdd := errors.New("stream"), errors.New("odd")
+		src := func() <-chan *Result[int] {
+			return Stream(func(_ context.Context, output chan<- *Result[int]) {
+				output <- NewResult(2, nil)
+				output <- NewResult(0, errStream)
+				output <- NewResult(3, nil)
+				output <- NewResult(4, nil)
+			})
+		}
+		evens := func(_ context.Context, total int, value int) (int, error) {
+			if value%2 == 1 {
+				return -1, errOdd
+			}
+			return total + value, nil
+		}
+
+		total, err := Fold(src(), 0, evens, WithErrorPolicy(FailFast))
+		require.Equal(t, errStream, err)
+		require.Equal(t, 2, total)
+
+		total, err = Fold(src(), 0, evens, WithErrorPolicy(SkipErrors))
+		require.NoError(t, err)
+		require.Equal(t, 6, total)
+
+		total, err = Fold(src(), 0, evens, WithErrorPolicy(CollectErrors))
+		require.ErrorIs(t, err, errStream)
+		require.ErrorIs(t, err, errOdd)
+		require.Equal(t, 6, total)
+	})
 }
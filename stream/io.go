@@ -0,0 +1,112 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+)
+
+// WithMaxTokenSize sets the largest token FromReader accepts, which defaults
+// to bufio.MaxScanTokenSize. a longer token ends the stream with an error
+// Result holding bufio.ErrTooLong.
+func WithMaxTokenSize(size int) Option {
+	return func(opts *options) {
+		opts.maxTokenSize = size
+	}
+}
+
+// FromReader sends the tokens of a reader, as split by a bufio.Scanner. a nil
+// split reads lines, as bufio.ScanLines.
+//
+// each token is a copy, safe to keep. a read or split error is sent as a final
+// error Result, since the scanner can not continue past it.
+//
+// the reader is not closed, and a cancelled context is only noticed between
+// tokens.
+func FromReader(r io.Reader, split bufio.SplitFunc, opts ...Option) <-chan *Result[[]byte] {
+	maxTokenSize := newOptions(opts...).maxTokenSize
+
+	return Stream(func(ctx context.Context, output chan<- *Result[[]byte]) {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(nil, maxTokenSize)
+		if split != nil {
+			scanner.Split(split)
+		}
+
+		for scanner.Scan() {
+			token := bytes.Clone(scanner.Bytes())
+			if !send(ctx, output, NewResult(token, nil)) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			send(ctx, output, NewResult[[]byte](nil, fmt.Errorf("stream.FromReader - %w", err)))
+		}
+	}, opts...)
+}
+
+// ToWriter encodes each value onto a buffered writer, flushing it once the
+// input is done, and returns the first error.
+//
+// WithErrorPolicy applies to errors from both the stream and encode, as it
+// does for Fold, while a write error always stops. the input is drained in the
+// background when ToWriter stops early.
+//
+// note: only the WithContext and WithErrorPolicy options have any effect. a
+// cancelled context stops the write, even while it waits on an idle input, and
+// returns the context's error.
+func ToWriter[T any](
+	input <-chan *Result[T],
+	w io.Writer,
+	encode func(value T) ([]byte, error),
+	opts ...Option,
+) (err error) {
+	options := newOptions(opts...)
+	buffered := bufio.NewWriter(w)
+
+	defer func() {
+		go Drain(input)
+		if flushErr := buffered.Flush(); err == nil {
+			err = flushErr
+		}
+	}()
+
+	errs := &errorTolerance{policy: options.errorPolicy}
+
+	for {
+		var result *Result[T]
+		var ok bool
+		select {
+		case <-options.ctx.Done():
+			return options.ctx.Err()
+		case result, ok = <-input:
+		}
+		if !ok {
+			break
+		}
+		// a value and the cancellation may arrive together.
+		if options.ctx.Err() != nil {
+			return options.ctx.Err()
+		}
+		if result.Error != nil {
+			if !errs.tolerate(result.Error) {
+				return result.Error
+			}
+			continue
+		}
+		encoded, err := encode(result.Value)
+		if err != nil {
+			if !errs.tolerate(err) {
+				return err
+			}
+			continue
+		}
+		if _, err := buffered.Write(encoded); err != nil {
+			return fmt.Errorf("stream.ToWriter - %w", err)
+		}
+	}
+
+	return errs.err()
+}
@@ -0,0 +1,119 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// failingWriter fails every write.
+type failingWriter struct{ err error }
+
+func (w failingWriter) Write([]byte) (int, error) { return 0, w.err }
+
+func TestFromReader(t *testing.T) {
+	t.Run("lines", func(t *testing.T) {
+		tokens := FromReader(strings.NewReader("a\nbb\n\nccc"), nil)
+		require.Equal(t, NewResult([]byte("a"), nil), <-tokens)
+		require.Equal(t, NewResult([]byte("bb"), nil), <-tokens)
+		require.Equal(t, NewResult([]byte{}, nil), <-tokens)
+		require.Equal(t, NewResult([]byte("ccc"), nil), <-tokens)
+		validateChannel(t, nil, false, tokens)
+	})
+
+	t.Run("split", func(t *testing.T) {
+		tokens := FromReader(strings.NewReader("one two  three"), bufio.ScanWords)
+		require.Equal(t, NewResult([]byte("one"), nil), <-tokens)
+		require.Equal(t, NewResult([]byte("two"), nil), <-tokens)
+		require.Equal(t, NewResult([]byte("three"), nil), <-tokens)
+		validateChannel(t, nil, false, tokens)
+	})
+
+	t.Run("token too long", func(t *testing.T) {
+		tokens := FromReader(strings.NewReader("short\n"+strings.Repeat("x", 100)+"\n"),
+			nil, WithMaxTokenSize(16))
+		require.Equal(t, NewResult([]byte("short"), nil), <-tokens)
+		require.ErrorIs(t, (<-tokens).Error, bufio.ErrTooLong)
+		validateChannel(t, nil, false, tokens)
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		err := errors.New("generic error")
+		tokens := FromReader(readerFunc(func([]byte) (int, error) { return 0, err }), nil)
+		require.ErrorIs(t, (<-tokens).Error, err)
+		validateChannel(t, nil, false, tokens)
+	})
+}
+
+// readerFunc adapts a function to io.Reader.
+type readerFunc func(p []byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) { return f(p) }
+
+func TestToWriter(t *testing.T) {
+	err := errors.New("generic error")
+	line := func(value int) ([]byte, error) {
+		return fmt.Appendf(nil, "%d\n", value), nil
+	}
+	numbers := func() <-chan *Result[int] {
+		return Stream(func(_ context.Context, output chan<- *Result[int]) {
+			output <- NewResult(1, nil)
+			output <- NewResult(0, err)
+			output <- NewResult(3, nil)
+		})
+	}
+
+	t.Run("writes", func(t *testing.T) {
+		var buffer bytes.Buffer
+		require.NoError(t, ToWriter(Range(1, 4), &buffer, line))
+		require.Equal(t, "1\n2\n3\n", buffer.String())
+	})
+
+	t.Run("fails fast", func(t *testing.T) {
+		var buffer bytes.Buffer
+		require.ErrorIs(t, ToWriter(numbers(), &buffer, line), err)
+		require.Equal(t, "1\n", buffer.String())
+	})
+
+	t.Run("collects errors", func(t *testing.T) {
+		var buffer bytes.Buffer
+		require.ErrorIs(t,
+			ToWriter(numbers(), &buffer, line, WithErrorPolicy(CollectErrors)), err)
+		require.Equal(t, "1\n3\n", buffer.String())
+	})
+
+	t.Run("encode error", func(t *testing.T) {
+		var buffer bytes.Buffer
+		require.NoError(t, ToWriter(Range(1, 4), &buffer,
+			func(value int) ([]byte, error) {
+				if value == 2 {
+					return nil, err
+				}
+				return line(value)
+			}, WithErrorPolicy(SkipErrors)))
+		require.Equal(t, "1\n3\n", buffer.String())
+	})
+
+	t.Run("context canceled while idle", func(t *testing.T) {
+		idle := make(chan *Result[int])
+		defer close(idle)
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(time.Millisecond, cancel)
+
+		var buffer bytes.Buffer
+		require.ErrorIs(t, ToWriter(idle, &buffer, line, WithContext(ctx)), context.Canceled)
+		require.Empty(t, buffer.String())
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		require.ErrorIs(t,
+			ToWriter(FromSlice([]int{1}), failingWriter{err}, line), err)
+	})
+}
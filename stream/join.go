@@ -0,0 +1,253 @@
+package stream
+
+import (
+	"context"
+	"errors"
+)
+
+// Pair is a pair of values combined from two streams.
+type Pair[A, B any] struct {
+	Left  A
+	Right B
+}
+
+// Zip pairs the values of two streams by position. it ends when either input
+// closes, draining the other in the background.
+//
+// an error on either side is sent in place of the pair, joined with the error
+// of the other side, if any.
+func Zip[A, B any](
+	left <-chan *Result[A],
+	right <-chan *Result[B],
+	opts ...Option,
+) <-chan *Result[Pair[A, B]] {
+	return Stream(func(ctx context.Context, output chan<- *Result[Pair[A, B]]) {
+		defer func() {
+			go Drain(left)
+			go Drain(right)
+		}()
+
+		for {
+			a, ok := <-left
+			if !ok {
+				return
+			}
+			b, ok := <-right
+			if !ok {
+				return
+			}
+
+			next := NewResult(Pair[A, B]{a.Value, b.Value}, nil)
+			if a.Error != nil || b.Error != nil {
+				next = NewResult(Pair[A, B]{}, errors.Join(a.Error, b.Error))
+			}
+			if !send(ctx, output, next) {
+				return
+			}
+		}
+	}, opts...)
+}
+
+// JoinKind selects which values a join sends when they have no match.
+type JoinKind int
+
+const (
+	// InnerJoin only sends matched pairs.
+	InnerJoin JoinKind = iota
+	// LeftJoin also sends the unmatched values of the left input, paired with
+	// nil.
+	LeftJoin
+	// OuterJoin also sends the unmatched values of both inputs, paired with
+	// nil.
+	OuterJoin
+)
+
+// joinEntry is a value held by a join, with its key and whether it has been
+// paired.
+type joinEntry[T any, K comparable] struct {
+	value   T
+	key     K
+	matched bool
+}
+
+// Join pairs the values of two streams by key, as a hash join.
+//
+// the right input is read in full and held in memory first, so both inputs
+// must be bounded. use WindowJoin for unbounded streams. a value matching
+// several values of the other side is sent in a pair with each of them.
+//
+// error Results from either side are passed on.
+func Join[A, B any, K comparable](
+	left <-chan *Result[A],
+	right <-chan *Result[B],
+	leftKey func(value A) K,
+	rightKey func(value B) K,
+	kind JoinKind,
+	opts ...Option,
+) <-chan *Result[Pair[*A, *B]] {
+	return Stream(func(ctx context.Context, output chan<- *Result[Pair[*A, *B]]) {
+		defer func() {
+			go Drain(left)
+			go Drain(right)
+		}()
+
+		var entries []*joinEntry[B, K]
+		table := map[K][]*joinEntry[B, K]{}
+		for result := range right {
+			if result.Error != nil {
+				if !send(ctx, output, NewResult(Pair[*A, *B]{}, result.Error)) {
+					return
+				}
+				continue
+			}
+			entry := &joinEntry[B, K]{value: result.Value, key: rightKey(result.Value)}
+			entries = append(entries, entry)
+			table[entry.key] = append(table[entry.key], entry)
+		}
+
+		for result := range left {
+			if result.Error != nil {
+				if !send(ctx, output, NewResult(Pair[*A, *B]{}, result.Error)) {
+					return
+				}
+				continue
+			}
+
+			matches := table[leftKey(result.Value)]
+			if len(matches) == 0 && kind != InnerJoin {
+				if !send(ctx, output, NewResult(Pair[*A, *B]{&result.Value, nil}, nil)) {
+					return
+				}
+			}
+			for _, match := range matches {
+				match.matched = true
+				if !send(ctx, output, NewResult(Pair[*A, *B]{&result.Value, &match.value}, nil)) {
+					return
+				}
+			}
+		}
+
+		if kind == OuterJoin {
+			for _, entry := range entries {
+				if !entry.matched && !send(ctx, output, NewResult(Pair[*A, *B]{nil, &entry.value}, nil)) {
+					return
+				}
+			}
+		}
+	}, opts...)
+}
+
+// WindowJoin pairs the values of two unbounded streams by key, using bounded
+// memory.
+//
+// each side holds only its most recent size values, and a new value is paired
+// with the matching values held by the other side. a value that leaves the
+// window unmatched is sent alone, as the JoinKind requires, as are those still
+// unmatched when both inputs close.
+//
+// error Results from either side are passed on.
+func WindowJoin[A, B any, K comparable](
+	left <-chan *Result[A],
+	right <-chan *Result[B],
+	leftKey func(value A) K,
+	rightKey func(value B) K,
+	kind JoinKind,
+	size int,
+	opts ...Option,
+) <-chan *Result[Pair[*A, *B]] {
+	size = max(size, 1)
+
+	return Stream(func(ctx context.Context, output chan<- *Result[Pair[*A, *B]]) {
+		defer func() {
+			go Drain(left)
+			go Drain(right)
+		}()
+
+		var lefts []*joinEntry[A, K]
+		var rights []*joinEntry[B, K]
+		// unmatched sends the values that are leaving unpaired.
+		unmatched := func(lefts []*joinEntry[A, K], rights []*joinEntry[B, K]) bool {
+			for _, entry := range lefts {
+				if !entry.matched && kind != InnerJoin &&
+					!send(ctx, output, NewResult(Pair[*A, *B]{&entry.value, nil}, nil)) {
+					return false
+				}
+			}
+			for _, entry := range rights {
+				if !entry.matched && kind == OuterJoin &&
+					!send(ctx, output, NewResult(Pair[*A, *B]{nil, &entry.value}, nil)) {
+					return false
+				}
+			}
+			return true
+		}
+
+		leftInput, rightInput := left, right
+		for leftInput != nil || rightInput != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case result, ok := <-leftInput:
+				if !ok {
+					leftInput = nil
+					continue
+				}
+				if result.Error != nil {
+					if !send(ctx, output, NewResult(Pair[*A, *B]{}, result.Error)) {
+						return
+					}
+					continue
+				}
+
+				entry := &joinEntry[A, K]{value: result.Value, key: leftKey(result.Value)}
+				for _, match := range rights {
+					if match.key != entry.key {
+						continue
+					}
+					entry.matched, match.matched = true, true
+					if !send(ctx, output, NewResult(Pair[*A, *B]{&entry.value, &match.value}, nil)) {
+						return
+					}
+				}
+				lefts = append(lefts, entry)
+				if len(lefts) > size {
+					if !unmatched(lefts[:1], nil) {
+						return
+					}
+					lefts = lefts[1:]
+				}
+			case result, ok := <-rightInput:
+				if !ok {
+					rightInput = nil
+					continue
+				}
+				if result.Error != nil {
+					if !send(ctx, output, NewResult(Pair[*A, *B]{}, result.Error)) {
+						return
+					}
+					continue
+				}
+
+				entry := &joinEntry[B, K]{value: result.Value, key: rightKey(result.Value)}
+				for _, match := range lefts {
+					if match.key != entry.key {
+						continue
+					}
+					entry.matched, match.matched = true, true
+					if !send(ctx, output, NewResult(Pair[*A, *B]{&match.value, &entry.value}, nil)) {
+						return
+					}
+				}
+				rights = append(rights, entry)
+				if len(rights) > size {
+					if !unmatched(nil, rights[:1]) {
+						return
+					}
+					rights = rights[1:]
+				}
+			}
+		}
+
+		unmatched(lefts, rights)
+	}, opts...)
+}
@@ -0,0 +1,204 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestZip(t *testing.T) {
+	err := errors.New("generic error")
+
+	t.Run("pairs by position", func(t *testing.T) {
+		letters := Stream(func(_ context.Context, output chan<- *Result[string]) {
+			output <- NewResult("a", nil)
+			output <- NewResult("b", nil)
+			output <- NewResult("c", nil)
+		})
+		numbers := Stream(func(_ context.Context, output chan<- *Result[int]) {
+			output <- NewResult(1, nil)
+			output <- NewResult(0, err)
+			output <- NewResult(3, nil)
+			output <- NewResult(4, nil)
+		})
+
+		zipped := Zip(letters, numbers)
+		require.Equal(t, NewResult(Pair[string, int]{"a", 1}, nil), <-zipped)
+		require.ErrorIs(t, (<-zipped).Error, err)
+		require.Equal(t, NewResult(Pair[string, int]{"c", 3}, nil), <-zipped)
+		validateChannel(t, nil, false, zipped)
+	})
+
+	t.Run("drains the longer input", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+
+		short := Stream(func(_ context.Context, output chan<- *Result[int]) {
+			output <- NewResult(1, nil)
+		})
+		zipped := Zip(short, counter(1000), WithBufferSize(0))
+		require.Equal(t, NewResult(Pair[int, int]{1, 0}, nil), <-zipped)
+		validateChannel(t, nil, false, zipped)
+
+		requireNoLeaks(t, baseline)
+	})
+}
+
+// user and order are the two sides of the join tests, keyed by user name.
+type user struct{ name string }
+type order struct {
+	user string
+	item string
+}
+
+// joined formats the pairs of a join, with - for a missing side.
+func joined(input <-chan *Result[Pair[*user, *order]]) []string {
+	results := []string{}
+	for result := range input {
+		if result.Error != nil {
+			results = append(results, "error: "+result.Error.Error())
+			continue
+		}
+		left, right := "-", "-"
+		if result.Value.Left != nil {
+			left = result.Value.Left.name
+		}
+		if result.Value.Right != nil {
+			right = fmt.Sprintf("%s/%s", result.Value.Right.user, result.Value.Right.item)
+		}
+		results = append(results, strings.Join([]string{left, right}, " "))
+	}
+	return results
+}
+
+func TestJoin(t *testing.T) {
+	err := errors.New("generic error")
+	users := func() <-chan *Result[user] {
+		return Stream(func(_ context.Context, output chan<- *Result[user]) {
+			output <- NewResult(user{"ann"}, nil)
+			output <- NewResult(user{"bob"}, nil)
+			output <- NewResult(user{}, err)
+			output <- NewResult(user{"cid"}, nil)
+		})
+	}
+	orders := func() <-chan *Result[order] {
+		return Stream(func(_ context.Context, output chan<- *Result[order]) {
+			output <- NewResult(order{"ann", "tea"}, nil)
+			output <- NewResult(order{"dan", "jam"}, nil)
+			output <- NewResult(order{"ann", "pie"}, nil)
+			output <- NewResult(order{"cid", "egg"}, nil)
+		})
+	}
+	userName := func(u user) string { return u.name }
+	orderUser := func(o order) string { return o.user }
+
+	for _, test := range []struct {
+		kind     JoinKind
+		expected []string
+	}{
+		{InnerJoin, []string{
+			"ann ann/tea", "ann ann/pie", "error: generic error", "cid cid/egg",
+		}},
+		{LeftJoin, []string{
+			"ann ann/tea", "ann ann/pie", "bob -", "error: generic error", "cid cid/egg",
+		}},
+		{OuterJoin, []string{
+			"ann ann/tea", "ann ann/pie", "bob -", "error: generic error", "cid cid/egg", "- dan/jam",
+		}},
+	} {
+		t.Run(fmt.Sprintf("kind %d", test.kind), func(t *testing.T) {
+			require.Equal(t, test.expected,
+				joined(Join(users(), orders(), userName, orderUser, test.kind)))
+		})
+	}
+
+	t.Run("cancelable", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		same := func(value int) int { return value }
+		pairs := Join(counter(1000), counter(10), same, same, InnerJoin,
+			WithContext(ctx), WithBufferSize(0))
+		<-pairs
+		cancel()
+
+		requireNoLeaks(t, baseline)
+	})
+}
+
+func TestWindowJoin(t *testing.T) {
+	userName := func(u user) string { return u.name }
+	orderUser := func(o order) string { return o.user }
+
+	// run feeds the values to the join one at a time, in the given order, so
+	// that the windows are deterministic.
+	run := func(kind JoinKind, size int, values ...any) []string {
+		users := make(chan *Result[user])
+		orders := make(chan *Result[order])
+		pairs := WindowJoin(users, orders, userName, orderUser, kind, size)
+		go func() {
+			defer close(users)
+			defer close(orders)
+			for _, value := range values {
+				switch value := value.(type) {
+				case user:
+					users <- NewResult(value, nil)
+				case order:
+					orders <- NewResult(value, nil)
+				}
+			}
+		}()
+		return joined(pairs)
+	}
+
+	t.Run("matches within the window", func(t *testing.T) {
+		require.Equal(t, []string{"ann ann/tea", "ann ann/pie"},
+			run(InnerJoin, 2,
+				user{"ann"}, order{"ann", "tea"}, user{"bob"}, order{"ann", "pie"}))
+	})
+
+	t.Run("evicted values no longer match", func(t *testing.T) {
+		require.Equal(t, []string{},
+			run(InnerJoin, 1,
+				user{"ann"}, user{"bob"}, order{"ann", "tea"}))
+	})
+
+	t.Run("left", func(t *testing.T) {
+		require.Equal(t, []string{"ann -", "bob bob/pie", "cid -"},
+			run(LeftJoin, 1,
+				user{"ann"}, order{"dan", "tea"}, user{"bob"}, order{"bob", "pie"}, user{"cid"}))
+	})
+
+	t.Run("outer", func(t *testing.T) {
+		require.Equal(t, []string{"- dan/tea", "ann -", "- eve/jam", "ann -", "- eve/egg"},
+			run(OuterJoin, 1,
+				order{"dan", "tea"}, user{"ann"}, order{"eve", "jam"}, user{"ann"}, order{"eve", "egg"}))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		err := errors.New("generic error")
+		users := Stream(func(_ context.Context, output chan<- *Result[user]) {
+			output <- NewResult(user{}, err)
+		})
+		orders := Stream(func(_ context.Context, output chan<- *Result[order]) {})
+		require.Equal(t, []string{"error: generic error"},
+			joined(WindowJoin(users, orders, userName, orderUser, InnerJoin, 1)))
+	})
+
+	t.Run("cancelable", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		same := func(value int) int { return value }
+		pairs := WindowJoin(counter(1000), counter(1000), same, same, InnerJoin, 10,
+			WithContext(ctx), WithBufferSize(0))
+		<-pairs
+		cancel()
+
+		requireNoLeaks(t, baseline)
+	})
+}
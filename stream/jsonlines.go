@@ -0,0 +1,71 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+)
+
+// DecodeJSONLines reads a JSON Lines reader one line at a time, sending each
+// line decoded into a T. blank lines are skipped.
+//
+// a line that fails to decode is sent as an error Result and decoding carries
+// on. a read error, including a line longer than WithMaxTokenSize, ends the
+// stream. errors hold a LineError, traced by TraceStage.
+func DecodeJSONLines[T any](r io.Reader, opts ...Option) <-chan *Result[T] {
+	maxTokenSize := newOptions(opts...).maxTokenSize
+
+	return Stream(func(ctx context.Context, output chan<- *Result[T]) {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(nil, maxTokenSize)
+
+		line := 0
+		for scanner.Scan() {
+			line++
+			if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+				continue
+			}
+
+			var value T
+			err := lineError(line, json.Unmarshal(scanner.Bytes(), &value))
+			if !send(ctx, output, NewResult(TraceStage("DecodeJSONLines", value, err))) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			err = lineError(line+1, err)
+			send(ctx, output, NewResult(TraceStage("DecodeJSONLines", *new(T), err)))
+		}
+	}, opts...)
+}
+
+// EncodeJSONLines encodes each value as a line of JSON, ready for ToWriter.
+//
+// error Results are passed on, and a value that fails to encode is sent as an
+// error Result holding a LineError with its output line number, traced by
+// TraceStage.
+func EncodeJSONLines[T any](input <-chan *Result[T], opts ...Option) <-chan *Result[[]byte] {
+	return Stream(func(ctx context.Context, output chan<- *Result[[]byte]) {
+		// line is the next line to be written. a value that fails to encode
+		// writes nothing, so it doesn't use one up.
+		line := 1
+		for result := range input {
+			next := NewResult[[]byte](nil, result.Error)
+			if result.Error == nil {
+				encoded, err := json.Marshal(result.Value)
+				if err != nil {
+					next = NewResult(TraceStage("EncodeJSONLines", []byte(nil), lineError(line, err)))
+				} else {
+					next = NewResult(append(encoded, '\n'), nil)
+					line++
+				}
+			}
+			if !send(ctx, output, next) {
+				go Drain(input)
+				return
+			}
+		}
+	}, opts...)
+}
@@ -0,0 +1,89 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// record is the value decoded by the codec tests.
+type record struct {
+	Name  string  `json:"name" csv:"name"`
+	Count int     `json:"count" csv:"count"`
+	Score float64 `json:"score" csv:"score"`
+	Note  string  `json:"-" csv:"-"`
+}
+
+func TestDecodeJSONLines(t *testing.T) {
+	t.Run("decodes", func(t *testing.T) {
+		input := `{"name":"a","count":1,"score":0.5}
+
+{"name":"b","count":2}
+`
+		values := DecodeJSONLines[record](strings.NewReader(input))
+		require.Equal(t, NewResult(record{"a", 1, 0.5, ""}, nil), <-values)
+		require.Equal(t, NewResult(record{Name: "b", Count: 2}, nil), <-values)
+		validateChannel(t, nil, false, values)
+	})
+
+	t.Run("bad line", func(t *testing.T) {
+		input := `{"name":"a"}
+{"name":
+{"name":"c"}`
+		values := DecodeJSONLines[record](strings.NewReader(input))
+		require.Equal(t, NewResult(record{Name: "a"}, nil), <-values)
+		requireLine(t, (<-values).Error, "DecodeJSONLines", 2)
+		require.Equal(t, NewResult(record{Name: "c"}, nil), <-values)
+		validateChannel(t, nil, false, values)
+	})
+
+	t.Run("line too long", func(t *testing.T) {
+		input := `{"name":"a"}` + "\n" + `{"name":"` + strings.Repeat("x", 100) + `"}`
+		values := DecodeJSONLines[record](strings.NewReader(input), WithMaxTokenSize(32))
+		require.Equal(t, NewResult(record{Name: "a"}, nil), <-values)
+		err := (<-values).Error
+		require.ErrorIs(t, err, bufio.ErrTooLong)
+		requireLine(t, err, "DecodeJSONLines", 2)
+		validateChannel(t, nil, false, values)
+	})
+}
+
+func TestEncodeJSONLines(t *testing.T) {
+	err := errors.New("generic error")
+	values := Stream(func(_ context.Context, output chan<- *Result[any]) {
+		output <- NewResult[any](record{Name: "a", Count: 1}, nil)
+		output <- NewResult[any](nil, err)
+		output <- NewResult[any](func() {}, nil)
+		output <- NewResult[any](map[string]int{"b": 2}, nil)
+		output <- NewResult[any](make(chan int), nil)
+	})
+
+	lines := EncodeJSONLines(values)
+	require.Equal(t, NewResult([]byte(`{"name":"a","count":1,"score":0}`+"\n"), nil), <-lines)
+	require.Equal(t, NewResult[[]byte](nil, err), <-lines)
+	requireLine(t, (<-lines).Error, "EncodeJSONLines", 2)
+	require.Equal(t, NewResult([]byte(`{"b":2}`+"\n"), nil), <-lines)
+	// a failed value writes no line, so it doesn't count.
+	requireLine(t, (<-lines).Error, "EncodeJSONLines", 3)
+	validateChannel(t, nil, false, lines)
+}
+
+func TestJSONLinesRoundTrip(t *testing.T) {
+	records := []record{{"a", 1, 0.5, ""}, {"b", 2, 1.5, ""}}
+
+	var buffer bytes.Buffer
+	require.NoError(t, ToWriter(EncodeJSONLines(FromSlice(records)), &buffer,
+		func(line []byte) ([]byte, error) { return line, nil }))
+
+	decoded, err := Fold(DecodeJSONLines[record](&buffer), nil,
+		func(_ context.Context, acc []record, value record) ([]record, error) {
+			return append(acc, value), nil
+		})
+	require.NoError(t, err)
+	require.Equal(t, records, decoded)
+}
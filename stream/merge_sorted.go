@@ -0,0 +1,101 @@
+package stream
+
+import (
+	"container/heap"
+	"context"
+)
+
+// mergeHead is the next value of one input of MergeSorted.
+type mergeHead[T any] struct {
+	value T
+	input int
+}
+
+// mergeHeap orders the next value of each input.
+type mergeHeap[T any] struct {
+	heads []mergeHead[T]
+	less  func(a, b T) bool
+}
+
+func (h *mergeHeap[T]) Len() int { return len(h.heads) }
+func (h *mergeHeap[T]) Less(i, j int) bool {
+	// ties go to the earlier input, keeping the merge stable.
+	if h.less(h.heads[i].value, h.heads[j].value) {
+		return true
+	}
+	if h.less(h.heads[j].value, h.heads[i].value) {
+		return false
+	}
+	return h.heads[i].input < h.heads[j].input
+}
+func (h *mergeHeap[T]) Swap(i, j int) { h.heads[i], h.heads[j] = h.heads[j], h.heads[i] }
+func (h *mergeHeap[T]) Push(x any)    { h.heads = append(h.heads, x.(mergeHead[T])) }
+func (h *mergeHeap[T]) Pop() any {
+	last := h.heads[len(h.heads)-1]
+	h.heads = h.heads[:len(h.heads)-1]
+	return last
+}
+
+// MergeSorted merges inputs that are each sorted by less into a single sorted
+// stream. unlike Multiplex, the order is deterministic: equal values are sent
+// in the order of their inputs.
+//
+// only one value per input is held at a time, so an input that is slow to send
+// holds up the merge. error Results are passed on as soon as they are read.
+//
+// If the context is cancelled, the output is closed and every input is drained
+// in the background.
+func MergeSorted[T any](
+	inputs []<-chan *Result[T],
+	less func(a, b T) bool,
+	opts ...Option,
+) <-chan *Result[T] {
+	return Stream(func(ctx context.Context, output chan<- *Result[T]) {
+		defer func() {
+			for _, input := range inputs {
+				go Drain(input)
+			}
+		}()
+
+		// next reads the next value of an input onto the heap, passing on any
+		// errors before it.
+		heads := &mergeHeap[T]{less: less}
+		next := func(index int) bool {
+			for {
+				var result *Result[T]
+				var ok bool
+				select {
+				case <-ctx.Done():
+					return false
+				case result, ok = <-inputs[index]:
+				}
+				if !ok {
+					return true
+				}
+				if result.Error != nil {
+					if !send(ctx, output, result) {
+						return false
+					}
+					continue
+				}
+				heap.Push(heads, mergeHead[T]{result.Value, index})
+				return true
+			}
+		}
+
+		for index := range inputs {
+			if !next(index) {
+				return
+			}
+		}
+		for heads.Len() > 0 {
+			head := heap.Pop(heads).(mergeHead[T])
+			if !send(ctx, output, NewResult(head.value, nil)) {
+				return
+			}
+			if !next(head.input) {
+				return
+			}
+		}
+	}, opts...)
+}
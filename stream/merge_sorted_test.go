@@ -0,0 +1,86 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeSorted(t *testing.T) {
+	sorted := func(values ...int) <-chan *Result[int] {
+		return Stream(func(_ context.Context, output chan<- *Result[int]) {
+			for _, value := range values {
+				output <- NewResult(value, nil)
+			}
+		})
+	}
+	less := func(a, b int) bool { return a < b }
+
+	t.Run("merges in order", func(t *testing.T) {
+		merged := MergeSorted([]<-chan *Result[int]{
+			sorted(1, 4, 7, 10),
+			sorted(),
+			sorted(2, 3, 8),
+			sorted(0, 5, 6, 9, 11, 12),
+		}, less)
+
+		for i := range 13 {
+			require.Equal(t, NewResult(i, nil), <-merged)
+		}
+		validateChannel(t, nil, false, merged)
+	})
+
+	t.Run("stable", func(t *testing.T) {
+		type item struct{ key, input int }
+		source := func(input int, keys ...int) <-chan *Result[item] {
+			return Stream(func(_ context.Context, output chan<- *Result[item]) {
+				for _, key := range keys {
+					output <- NewResult(item{key, input}, nil)
+				}
+			})
+		}
+
+		merged := MergeSorted([]<-chan *Result[item]{
+			source(0, 1, 2),
+			source(1, 1, 2),
+		}, func(a, b item) bool { return a.key < b.key })
+
+		require.Equal(t, NewResult(item{1, 0}, nil), <-merged)
+		require.Equal(t, NewResult(item{1, 1}, nil), <-merged)
+		require.Equal(t, NewResult(item{2, 0}, nil), <-merged)
+		require.Equal(t, NewResult(item{2, 1}, nil), <-merged)
+		validateChannel(t, nil, false, merged)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		err := errors.New("generic error")
+		failing := Stream(func(_ context.Context, output chan<- *Result[int]) {
+			output <- NewResult(1, nil)
+			output <- NewResult(0, err)
+			output <- NewResult(3, nil)
+		})
+
+		merged := MergeSorted([]<-chan *Result[int]{failing, sorted(0, 2)}, less)
+		require.Equal(t, NewResult(0, nil), <-merged)
+		require.Equal(t, NewResult(1, nil), <-merged)
+		require.Equal(t, NewResult(0, err), <-merged)
+		require.Equal(t, NewResult(2, nil), <-merged)
+		require.Equal(t, NewResult(3, nil), <-merged)
+		validateChannel(t, nil, false, merged)
+	})
+
+	t.Run("cancelable", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		merged := MergeSorted([]<-chan *Result[int]{counter(1000), counter(1000)}, less,
+			WithContext(ctx), WithBufferSize(0))
+		require.Equal(t, NewResult(0, nil), <-merged)
+		cancel()
+
+		requireNoLeaks(t, baseline)
+	})
+}
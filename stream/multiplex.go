@@ -7,6 +7,9 @@ import (
 
 // Multiplex standardizes the fan-in case, where multiple process outputs are
 // merged into a single channel for simple processing.
+//
+// If the context is cancelled, the output is closed and every input is drained
+// in the background.
 func Multiplex[T any](inputs []<-chan T, opts ...Option) <-chan T {
 	return Stream(func(ctx context.Context, output chan<- T) {
 		var wg sync.WaitGroup
@@ -15,10 +18,9 @@ func Multiplex[T any](inputs []<-chan T, opts ...Option) <-chan T {
 			go func() {
 				defer wg.Done()
 				for value := range input {
-					select {
-					case <-ctx.Done():
+					if !send(ctx, output, value) {
+						go Drain(input)
 						return
-					case output <- value:
 					}
 				}
 			}()
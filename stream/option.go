@@ -1,6 +1,9 @@
 package stream
 
-import "context"
+import (
+	"bufio"
+	"context"
+)
 
 // defaultBufferSize provides a reasonable value for channel buffer sizes.
 const defaultBufferSize uint16 = 1000
@@ -8,13 +11,34 @@ const defaultBufferSize uint16 = 1000
 // options defines the possible configurations that may be modified via the
 // functional options pattern.
 type options struct {
-	ctx        context.Context
-	bufferSize uint16
+	ctx           context.Context
+	bufferSize    uint16
+	clock         Clock
+	errorPolicy   ErrorPolicy
+	deadLetters   DeadLetterSink
+	recoverPanics bool
+	maxTokenSize  int
 }
 
 // Option is a function that modifies the Options values.
 type Option func(*options)
 
+// newOptions applies the supplied options over the defaults.
+func newOptions(opts ...Option) *options {
+	options := &options{
+		ctx:           context.Background(),
+		bufferSize:    defaultBufferSize,
+		clock:         systemClock{},
+		recoverPanics: true,
+		maxTokenSize:  bufio.MaxScanTokenSize,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return options
+}
+
 // WithContext adds the supplied context to the options.
 func WithContext(ctx context.Context) Option {
 	return func(opts *options) {
@@ -28,3 +52,11 @@ func WithBufferSize(size uint16) Option {
 		opts.bufferSize = size
 	}
 }
+
+// WithClock replaces the wall clock used by time-based stages. it is mostly
+// useful for deterministic tests.
+func WithClock(clock Clock) Option {
+	return func(opts *options) {
+		opts.clock = clock
+	}
+}
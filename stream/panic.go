@@ -0,0 +1,76 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is a panic recovered from a function run by a stage, with the
+// stack of the goroutine that panicked. the stack is kept out of the message,
+// so it doesn't swell every log line that the error reaches.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (err *PanicError) Error() string {
+	return fmt.Sprintf("stream: panic: %v", err.Value)
+}
+
+// Unwrap returns the panic value if it was an error.
+func (err *PanicError) Unwrap() error {
+	if wrapped, ok := err.Value.(error); ok {
+		return wrapped
+	}
+	return nil
+}
+
+// WithPanicRecovery enables or disables the recovery of panics, which is
+// enabled by default. disable it to let a panic crash the process as usual.
+func WithPanicRecovery(enabled bool) Option {
+	return func(opts *options) {
+		opts.recoverPanics = enabled
+	}
+}
+
+// protect calls fn, converting a panic into a PanicError if enabled.
+//
+// Stream only recovers panics in its own goroutine, so a stage must protect the
+// callbacks it runs on any goroutine it starts itself.
+func protect[U any](enabled bool, fn func() (U, error)) (value U, err error) {
+	if enabled {
+		defer func() {
+			if r := recover(); r != nil {
+				err = &PanicError{Value: r, Stack: debug.Stack()}
+			}
+		}()
+	}
+	return fn()
+}
+
+// errorResulter lets Stream build an error Result without knowing the type of
+// its value. it is implemented by a nil *Result.
+type errorResulter interface {
+	errorResult(err error) any
+}
+
+func (*Result[T]) errorResult(err error) any {
+	return NewResult(*new(T), err)
+}
+
+// recoverResult is deferred by Stream to send a panic as a final error Result.
+// a channel of any other type has nowhere to report it, so the panic continues.
+func recoverResult[T any](ctx context.Context, output chan<- T) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	resulter, ok := any(*new(T)).(errorResulter)
+	if !ok {
+		panic(r)
+	}
+	err := &PanicError{Value: r, Stack: debug.Stack()}
+	send(ctx, output, resulter.errorResult(err).(T))
+}
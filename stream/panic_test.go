@@ -0,0 +1,242 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPanicRecovery(t *testing.T) {
+	errPanic := errors.New("panic error")
+	fragile := func(_ context.Context, input int) (int, error) {
+		switch input {
+		case 1:
+			panic("boom")
+		case 2:
+			panic(errPanic)
+		}
+		return input, nil
+	}
+	src := func() <-chan *Result[int] {
+		return Stream(func(_ context.Context, output chan<- *Result[int]) {
+			for i := range 4 {
+				output <- NewResult(i, nil)
+			}
+		})
+	}
+
+	t.Run("Stream", func(t *testing.T) {
+		output := Stream(func(_ context.Context, output chan<- *Result[int]) {
+			output <- NewResult(1, nil)
+			panic("boom")
+		})
+
+		require.Equal(t, NewResult(1, nil), <-output)
+		result := <-output
+		var panicErr *PanicError
+		require.ErrorAs(t, result.Error, &panicErr)
+		require.Equal(t, "boom", panicErr.Value)
+		require.Contains(t, string(panicErr.Stack), "panic_test.go")
+		require.Equal(t, "stream: panic: boom", panicErr.Error())
+		validateChannel(t, nil, false, output)
+	})
+
+	t.Run("Transform", func(t *testing.T) {
+		output := Transform(src(), fragile)
+
+		require.Equal(t, NewResult(0, nil), <-output)
+		var panicErr *PanicError
+		require.ErrorAs(t, (<-output).Error, &panicErr)
+		require.Equal(t, "boom", panicErr.Value)
+		// error values remain visible to errors.Is.
+		require.ErrorIs(t, (<-output).Error, errPanic)
+		require.Equal(t, NewResult(3, nil), <-output)
+		validateChannel(t, nil, false, output)
+	})
+
+	t.Run("ParallelTransform", func(t *testing.T) {
+		output := ParallelTransform(src(), 2, fragile)
+
+		require.Equal(t, NewResult(0, nil), <-output)
+		require.ErrorAs(t, (<-output).Error, new(*PanicError))
+		require.ErrorIs(t, (<-output).Error, errPanic)
+		require.Equal(t, NewResult(3, nil), <-output)
+		validateChannel(t, nil, false, output)
+	})
+
+	t.Run("Fold", func(t *testing.T) {
+		total, err := Fold(src(), 0,
+			func(ctx context.Context, total int, value int) (int, error) {
+				value, err := fragile(ctx, value)
+				return total + value, err
+			},
+			WithErrorPolicy(CollectErrors),
+		)
+
+		require.Equal(t, 3, total)
+		require.ErrorAs(t, err, new(*PanicError))
+		require.ErrorIs(t, err, errPanic)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		require.PanicsWithValue(t, "boom", func() {
+			Fold(src(), 0,
+				func(ctx context.Context, total int, value int) (int, error) {
+					return fragile(ctx, value)
+				},
+				WithPanicRecovery(false),
+			)
+		})
+	})
+}
+
+// panicked reads a stream to the end and returns the PanicErrors it sent.
+func panicked[T any](input <-chan *Result[T]) []*PanicError {
+	var errs []*PanicError
+	for result := range input {
+		var panicErr *PanicError
+		if errors.As(result.Error, &panicErr) {
+			errs = append(errs, panicErr)
+		}
+	}
+	return errs
+}
+
+// a callback that panics must not leave the producers of a stage blocked, nor
+// escape a goroutine of the stage's own.
+func TestPanicDrainsInputs(t *testing.T) {
+	boom := func(value int) bool {
+		if value == 1 {
+			panic("boom")
+		}
+		return true
+	}
+	key := func(value int) int {
+		boom(value)
+		return value
+	}
+	at := func(value int) time.Time {
+		boom(value)
+		return time.Unix(int64(value), 0)
+	}
+
+	t.Run("Filter keeps going", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+
+		filtered := Filter(counter(100), boom)
+		count := 0
+		var errs []error
+		for result := range filtered {
+			count++
+			if result.Error != nil {
+				errs = append(errs, result.Error)
+			}
+		}
+		require.Equal(t, 100, count)
+		require.Len(t, errs, 1)
+		require.ErrorAs(t, errs[0], new(*PanicError))
+
+		requireNoLeaks(t, baseline)
+	})
+
+	t.Run("BatchBy keeps going", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+
+		batches := BatchBy(counter(100), 10, func(value int) int {
+			boom(value)
+			return 1
+		})
+		require.Len(t, panicked(batches), 1)
+
+		requireNoLeaks(t, baseline)
+	})
+
+	for name, stage := range map[string]func() []*PanicError{
+		"TakeWhile": func() []*PanicError {
+			return panicked(TakeWhile(counter(100), boom, nil))
+		},
+		"Join": func() []*PanicError {
+			return panicked(Join(counter(100), counter(100), key, key, InnerJoin))
+		},
+		"WindowJoin": func() []*PanicError {
+			return panicked(WindowJoin(counter(100), counter(100), key, key, InnerJoin, 5))
+		},
+		"MergeSorted": func() []*PanicError {
+			return panicked(MergeSorted([]<-chan *Result[int]{counter(100), counter(100)},
+				func(a, b int) bool {
+					return key(a) < b
+				}))
+		},
+		"TumblingWindow": func() []*PanicError {
+			return panicked(TumblingWindow(counter(100), time.Second, at))
+		},
+	} {
+		t.Run(name+" drains", func(t *testing.T) {
+			baseline := runtime.NumGoroutine()
+			require.Len(t, stage(), 1)
+			requireNoLeaks(t, baseline)
+		})
+	}
+
+	t.Run("Watermark keeps going", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+
+		stamped := Watermark([]<-chan *Result[int]{counter(100), counter(100)}, at)
+		require.Len(t, panicked(stamped), 2)
+
+		requireNoLeaks(t, baseline)
+	})
+
+	t.Run("DistributeByKey keeps going", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+
+		channels := DistributeByKey(counter(100), 2, func(value *Result[int]) string {
+			boom(value.Value)
+			return strconv.Itoa(value.Value)
+		})
+		second := make(chan int)
+		go func() {
+			count := 0
+			for range channels[1] {
+				count++
+			}
+			second <- count
+		}()
+		count := 0
+		var errs []error
+		for result := range channels[0] {
+			count++
+			if result.Error != nil {
+				errs = append(errs, result.Error)
+			}
+		}
+		require.Equal(t, 100, count+<-second)
+		require.Len(t, errs, 1)
+		require.ErrorAs(t, errs[0], new(*PanicError))
+
+		requireNoLeaks(t, baseline)
+	})
+
+	t.Run("ParallelFold initial", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+
+		_, err := ParallelFold([]<-chan *Result[int]{counter(100), counter(100)},
+			func() int {
+				panic("boom")
+			},
+			func(_ context.Context, total int, value int) (int, error) {
+				return total + value, nil
+			},
+			func(a, b int) (int, error) {
+				return a + b, nil
+			})
+		require.ErrorAs(t, err, new(*PanicError))
+
+		requireNoLeaks(t, baseline)
+	})
+}
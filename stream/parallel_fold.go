@@ -0,0 +1,76 @@
+package stream
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// ParallelFold is a map-reduce Fold. each input, such as the outputs of
+// Distribute, is folded concurrently from its own initial value, then the
+// partial results are merged with combine, in the order of the inputs.
+//
+// the first error from any partition cancels the others and is returned with
+// the zero value. options apply to each partition's Fold, so WithErrorPolicy
+// decides which errors reach that point.
+func ParallelFold[T, U any](
+	inputs []<-chan *Result[T],
+	initial func() U,
+	aggregator func(ctx context.Context, accumulator U, value T) (U, error),
+	combine func(a, b U) (U, error),
+	opts ...Option,
+) (U, error) {
+	options := newOptions(opts...)
+	ctx, cancel := context.WithCancel(options.ctx)
+	defer cancel()
+	partitionOpts := append(slices.Clip(opts), WithContext(ctx))
+
+	partials := make([]U, len(inputs))
+	var once sync.Once
+	var firstErr error
+
+	var wg sync.WaitGroup
+	wg.Add(len(inputs))
+	for id, input := range inputs {
+		go func() {
+			defer wg.Done()
+			partial, err := protect(options.recoverPanics, func() (U, error) {
+				return initial(), nil
+			})
+			if err == nil {
+				partial, err = Fold(input, partial, aggregator, partitionOpts...)
+			} else {
+				go Drain(input)
+			}
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			partials[id] = partial
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return *new(U), firstErr
+	}
+	if len(partials) == 0 {
+		return initial(), nil
+	}
+
+	accumulator := partials[0]
+	for _, partial := range partials[1:] {
+		var err error
+		accumulator, err = protect(options.recoverPanics, func() (U, error) {
+			return combine(accumulator, partial)
+		})
+		if err != nil {
+			return *new(U), err
+		}
+	}
+
+	return accumulator, nil
+}
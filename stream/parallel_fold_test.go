@@ -0,0 +1,138 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParallelFold(t *testing.T) {
+	sum := func(_ context.Context, total int, value int) (int, error) {
+		return total + value, nil
+	}
+	add := func(a, b int) (int, error) {
+		return a + b, nil
+	}
+	zero := func() int {
+		return 0
+	}
+
+	t.Run("map reduce", func(t *testing.T) {
+		src := Stream(func(_ context.Context, output chan<- *Result[int]) {
+			for i := range 1000 {
+				output <- NewResult(i, nil)
+			}
+		})
+
+		total, err := ParallelFold(Distribute(src, 4), zero, sum, add)
+		require.NoError(t, err)
+		require.Equal(t, 499500, total)
+	})
+
+	t.Run("independent accumulators", func(t *testing.T) {
+		src := Stream(func(_ context.Context, output chan<- *Result[string]) {
+			for _, word := range []string{"a", "b", "a", "c", "a", "b"} {
+				output <- NewResult(word, nil)
+			}
+		})
+
+		counts, err := ParallelFold(
+			Distribute(src, 3),
+			func() map[string]int {
+				return map[string]int{}
+			},
+			func(
+				_ context.Context,
+				counts map[string]int,
+				word string,
+			) (map[string]int, error) {
+				counts[word]++
+				return counts, nil
+			},
+			func(a, b map[string]int) (map[string]int, error) {
+				for word, count := range b {
+					a[word] += count
+				}
+				return a, nil
+			},
+		)
+		require.NoError(t, err)
+		require.Equal(t, map[string]int{"a": 3, "b": 2, "c": 1}, counts)
+	})
+
+	t.Run("first error cancels", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+		errBad := errors.New("bad value")
+		var folded atomic.Int64
+
+		inputs := []<-chan *Result[int]{
+			Stream(func(_ context.Context, output chan<- *Result[int]) {
+				output <- NewResult(0, errBad)
+			}),
+			counter(1000),
+			counter(1000),
+		}
+		total, err := ParallelFold(inputs, zero,
+			func(_ context.Context, total int, value int) (int, error) {
+				folded.Add(1)
+				time.Sleep(time.Millisecond)
+				return total + value, nil
+			},
+			add,
+		)
+		require.Equal(t, errBad, err)
+		require.Zero(t, total)
+		require.Less(t, folded.Load(), int64(2000))
+
+		requireNoLeaks(t, baseline)
+	})
+
+	t.Run("first error cancels an idle partition", func(t *testing.T) {
+		errBad := errors.New("bad value")
+		// idle never sends nor closes, as a quiet upstream would.
+		idle := make(chan *Result[int])
+		defer close(idle)
+
+		done := make(chan error)
+		go func() {
+			_, err := ParallelFold([]<-chan *Result[int]{
+				Stream(func(_ context.Context, output chan<- *Result[int]) {
+					output <- NewResult(0, errBad)
+				}),
+				idle,
+			}, zero, sum, add)
+			done <- err
+		}()
+
+		select {
+		case err := <-done:
+			require.Equal(t, errBad, err)
+		case <-time.After(time.Second):
+			t.Fatal("ParallelFold waited on the idle partition")
+		}
+	})
+
+	t.Run("combine error", func(t *testing.T) {
+		errCombine := errors.New("combine")
+		_, err := ParallelFold(
+			[]<-chan *Result[int]{counter(10), counter(10)},
+			zero,
+			sum,
+			func(a, b int) (int, error) {
+				return 0, errCombine
+			},
+		)
+		require.Equal(t, errCombine, err)
+	})
+
+	t.Run("no inputs", func(t *testing.T) {
+		total, err := ParallelFold(nil, zero, sum, add)
+		require.NoError(t, err)
+		require.Zero(t, total)
+	})
+}
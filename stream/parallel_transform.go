@@ -0,0 +1,78 @@
+package stream
+
+import "context"
+
+// ParallelTransform is a Transform that runs the transform function on workers
+// concurrent goroutines while emitting the results in the same order as the
+// input.
+//
+// it replaces the Distribute, Processor, Multiplex pattern when the order of
+// the stream matters. error Results bypass the workers and keep their position.
+//
+// the reorder window is bounded by the worker count: at most workers+2 values
+// are held, counting the reserved slots, the one being emitted and the one the
+// feeder is waiting to place, so a single slow value stalls the stream rather
+// than growing memory.
+//
+// WithDeadLetters and panic recovery apply as they do to Transform.
+func ParallelTransform[T, U any](
+	input <-chan *Result[T],
+	workers int,
+	transform func(ctx context.Context, input T) (U, error),
+	opts ...Option,
+) <-chan *Result[U] {
+	type job struct {
+		value T
+		slot  chan<- *Result[U]
+	}
+
+	workers = max(workers, 1)
+	options := newOptions(opts...)
+
+	return Stream(func(ctx context.Context, output chan<- *Result[U]) {
+		jobs := make(chan job)
+		// each value reserves a slot, in input order, that its worker fills.
+		pending := make(chan chan *Result[U], workers)
+
+		for range workers {
+			go func() {
+				for j := range jobs {
+					value, err := protect(options.recoverPanics, func() (U, error) {
+						return transform(ctx, j.value)
+					})
+					err = deadLetter(options.deadLetters, j.value, err)
+					j.slot <- NewResult(value, err)
+				}
+			}()
+		}
+
+		go func() {
+			defer close(pending)
+			defer close(jobs)
+			for result := range input {
+				slot := make(chan *Result[U], 1)
+				if !send(ctx, pending, slot) {
+					go Drain(input)
+					return
+				}
+				if result.Error != nil {
+					slot <- NewResult(*new(U), result.Error)
+				} else if !send(ctx, jobs, job{result.Value, slot}) {
+					go Drain(input)
+					return
+				}
+			}
+		}()
+
+		for slot := range pending {
+			select {
+			case <-ctx.Done():
+				return
+			case result := <-slot:
+				if !send(ctx, output, result) {
+					return
+				}
+			}
+		}
+	}, opts...)
+}
@@ -0,0 +1,113 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParallelTransform(t *testing.T) {
+	t.Run("ordered", func(t *testing.T) {
+		src := Stream(func(_ context.Context, output chan<- *Result[int]) {
+			for i := range 100 {
+				output <- NewResult(i, nil)
+			}
+		})
+
+		// later values finish first, forcing a reorder.
+		transformed := ParallelTransform(src, 8,
+			func(_ context.Context, input int) (int, error) {
+				time.Sleep(time.Duration(10-input%10) * time.Millisecond)
+				return input * 2, nil
+			})
+
+		expected := 0
+		for result := range transformed {
+			require.Equal(t, NewResult(expected*2, nil), result)
+			expected++
+		}
+		require.Equal(t, 100, expected)
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		src := Stream(func(_ context.Context, output chan<- *Result[int]) {
+			for i := range 20 {
+				output <- NewResult(i, nil)
+			}
+		})
+
+		// the first 10 values wait for each other, so they only finish if all
+		// of them run at once.
+		var active, peak atomic.Int32
+		var started sync.WaitGroup
+		started.Add(10)
+		Drain(ParallelTransform(src, 10,
+			func(_ context.Context, input int) (int, error) {
+				current := active.Add(1)
+				defer active.Add(-1)
+				for {
+					previous := peak.Load()
+					if current <= previous || peak.CompareAndSwap(previous, current) {
+						break
+					}
+				}
+				if input < 10 {
+					started.Done()
+					started.Wait()
+				}
+				return input, nil
+			}))
+
+		require.Equal(t, int32(10), peak.Load())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		err := errors.New("generic error")
+		errOdd := errors.New("odd")
+		src := Stream(func(_ context.Context, output chan<- *Result[int]) {
+			output <- NewResult(1, nil)
+			output <- NewResult(2, nil)
+			output <- NewResult(0, err)
+			output <- NewResult(4, nil)
+			output <- NewResult(5, nil)
+		})
+
+		transformed := ParallelTransform(src, 3,
+			func(_ context.Context, input int) (int, error) {
+				if input%2 == 1 {
+					return 0, errOdd
+				}
+				return input, nil
+			})
+
+		require.Equal(t, NewResult(0, errOdd), <-transformed)
+		require.Equal(t, NewResult(2, nil), <-transformed)
+		require.Equal(t, NewResult(0, err), <-transformed)
+		require.Equal(t, NewResult(4, nil), <-transformed)
+		require.Equal(t, NewResult(0, errOdd), <-transformed)
+		validateChannel(t, nil, false, transformed)
+	})
+
+	t.Run("cancelable", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		transformed := ParallelTransform(counter(1000), 4,
+			func(_ context.Context, input int) (int, error) {
+				return input, nil
+			},
+			WithContext(ctx),
+			WithBufferSize(0),
+		)
+		require.Equal(t, NewResult(0, nil), <-transformed)
+		cancel()
+
+		requireNoLeaks(t, baseline)
+	})
+}
@@ -0,0 +1,123 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy configures Retry. the zero value is usable: 3 attempts with an
+// exponential backoff starting at 100ms.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of calls, including the first. defaults
+	// to 3.
+	MaxAttempts int
+	// InitialDelay is the wait before the first retry. defaults to 100ms.
+	InitialDelay time.Duration
+	// MaxDelay caps the wait between attempts. zero means no cap.
+	MaxDelay time.Duration
+	// Multiplier grows the delay after each retry. defaults to 2.
+	Multiplier float64
+	// Jitter randomly shortens each delay by up to this fraction, between 0
+	// and 1, so that concurrent workers don't retry in lockstep.
+	Jitter float64
+	// Retryable reports whether an error is worth another attempt. nil retries
+	// every error.
+	Retryable func(err error) bool
+	// Clock is the source of the delays. nil uses the wall clock.
+	Clock Clock
+}
+
+// RetryError is the final error of a call wrapped by Retry, with the number
+// of attempts made. retrieve it with errors.As.
+type RetryError struct {
+	Attempts int
+	// Err is the last failure, joined with the context's error if the retries
+	// were cancelled.
+	Err error
+}
+
+func (err *RetryError) Error() string {
+	noun := "attempts"
+	if err.Attempts == 1 {
+		noun = "attempt"
+	}
+	return fmt.Sprintf("stream.Retry - %d %s: %v", err.Attempts, noun, err.Err)
+}
+
+func (err *RetryError) Unwrap() error {
+	return err.Err
+}
+
+// delay returns the wait after the given failed attempt, counting from 1.
+func (policy RetryPolicy) delay(attempt int) time.Duration {
+	delay := float64(policy.InitialDelay)
+	if delay == 0 {
+		delay = float64(100 * time.Millisecond)
+	}
+	multiplier := policy.Multiplier
+	if multiplier == 0 {
+		multiplier = 2
+	}
+	for range attempt - 1 {
+		delay *= multiplier
+		if policy.MaxDelay > 0 && delay >= float64(policy.MaxDelay) {
+			break
+		}
+	}
+	if policy.MaxDelay > 0 {
+		delay = min(delay, float64(policy.MaxDelay))
+	}
+	if policy.Jitter > 0 {
+		delay -= delay * min(policy.Jitter, 1) * rand.Float64()
+	}
+
+	return time.Duration(delay)
+}
+
+// Retry wraps a Transform function so that failed calls are attempted again
+// with an exponential backoff.
+//
+// once the attempts are exhausted, or an error is not Retryable, the last error
+// is returned in a RetryError. the waits between attempts end early if the
+// context is cancelled, in which case the RetryError also holds the context's
+// error.
+func Retry[T, U any](
+	transform func(ctx context.Context, input T) (U, error),
+	policy RetryPolicy,
+) func(ctx context.Context, input T) (U, error) {
+	attempts := policy.MaxAttempts
+	if attempts == 0 {
+		attempts = 3
+	}
+	clock := policy.Clock
+	if clock == nil {
+		clock = systemClock{}
+	}
+
+	return func(ctx context.Context, input T) (U, error) {
+		var err error
+		for attempt := 1; ; attempt++ {
+			var value U
+			value, err = transform(ctx, input)
+			if err == nil {
+				return value, nil
+			}
+			if attempt >= attempts ||
+				(policy.Retryable != nil && !policy.Retryable(err)) {
+				return value, &RetryError{Attempts: attempt, Err: err}
+			}
+
+			select {
+			case <-ctx.Done():
+				return value, &RetryError{
+					Attempts: attempt,
+					Err:      errors.Join(err, ctx.Err()),
+				}
+			case <-clock.After(policy.delay(attempt)):
+			}
+		}
+	}
+}
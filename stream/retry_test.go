@@ -0,0 +1,128 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetry(t *testing.T) {
+	errTransient := errors.New("transient")
+	errFatal := errors.New("fatal")
+
+	// flaky fails the given number of times before succeeding.
+	flaky := func(failures int, err error) (
+		func(context.Context, int) (int, error),
+		*int,
+	) {
+		calls := 0
+		return func(_ context.Context, input int) (int, error) {
+			calls++
+			if calls <= failures {
+				return 0, err
+			}
+			return input * 2, nil
+		}, &calls
+	}
+
+	t.Run("recovers", func(t *testing.T) {
+		transform, calls := flaky(2, errTransient)
+		retried := Retry(transform, RetryPolicy{InitialDelay: time.Millisecond})
+
+		src := Stream(func(_ context.Context, output chan<- *Result[int]) {
+			output <- NewResult(21, nil)
+		})
+		require.Equal(t, NewResult(42, nil), <-Transform(src, retried))
+		require.Equal(t, 3, *calls)
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		transform, calls := flaky(5, errTransient)
+		retried := Retry(transform, RetryPolicy{
+			MaxAttempts:  4,
+			InitialDelay: time.Millisecond,
+		})
+
+		_, err := retried(context.Background(), 1)
+		require.ErrorIs(t, err, errTransient)
+		var retryErr *RetryError
+		require.ErrorAs(t, err, &retryErr)
+		require.Equal(t, 4, retryErr.Attempts)
+		require.ErrorContains(t, err, "stream.Retry - 4 attempts")
+		require.Equal(t, 4, *calls)
+	})
+
+	t.Run("not retryable", func(t *testing.T) {
+		transform, calls := flaky(5, errFatal)
+		retried := Retry(transform, RetryPolicy{
+			Retryable: func(err error) bool {
+				return errors.Is(err, errTransient)
+			},
+		})
+
+		_, err := retried(context.Background(), 1)
+		require.ErrorIs(t, err, errFatal)
+		var retryErr *RetryError
+		require.ErrorAs(t, err, &retryErr)
+		require.Equal(t, 1, retryErr.Attempts)
+		require.ErrorContains(t, err, "stream.Retry - 1 attempt:")
+		require.Equal(t, 1, *calls)
+	})
+
+	t.Run("backoff", func(t *testing.T) {
+		clock := newFakeClock()
+		transform, calls := flaky(3, errTransient)
+		policy := RetryPolicy{
+			MaxAttempts:  4,
+			InitialDelay: time.Second,
+			MaxDelay:     3 * time.Second,
+			Clock:        clock,
+		}
+		require.Equal(t, time.Second, policy.delay(1))
+		require.Equal(t, 2*time.Second, policy.delay(2))
+		require.Equal(t, 3*time.Second, policy.delay(3))
+		retried := Retry(transform, policy)
+
+		done := make(chan error)
+		go func() {
+			_, err := retried(context.Background(), 1)
+			done <- err
+		}()
+
+		// waits of 1s, 2s, then 4s capped to 3s.
+		for _, delay := range []time.Duration{1, 2, 3} {
+			clock.WaitForTimers(t, 1)
+			clock.Advance(delay * time.Second)
+		}
+		require.NoError(t, <-done)
+		require.Equal(t, 4, *calls)
+		require.Equal(t, time.Unix(6, 0), clock.Now())
+	})
+
+	t.Run("jitter", func(t *testing.T) {
+		policy := RetryPolicy{InitialDelay: time.Second, Jitter: 0.5}
+		for range 100 {
+			delay := policy.delay(2)
+			require.LessOrEqual(t, delay, 2*time.Second)
+			require.GreaterOrEqual(t, delay, time.Second)
+		}
+	})
+
+	t.Run("cancelable", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		transform, calls := flaky(5, errTransient)
+		retried := Retry(transform, RetryPolicy{InitialDelay: time.Hour})
+
+		time.AfterFunc(time.Millisecond, cancel)
+		_, err := retried(ctx, 1)
+		require.ErrorIs(t, err, errTransient)
+		require.ErrorIs(t, err, context.Canceled)
+		var retryErr *RetryError
+		require.ErrorAs(t, err, &retryErr)
+		require.Equal(t, 1, retryErr.Attempts)
+		require.Equal(t, 1, *calls)
+	})
+}
@@ -0,0 +1,48 @@
+package stream
+
+import "context"
+
+// Scan is a streaming Fold. it performs the aggregator function over the
+// contents of the input channel, beginning with the initialValue, and sends
+// every intermediate accumulator downstream.
+//
+// the aggregator matches that of Fold, so the two are interchangeable. errors
+// from the stream or the aggregator are forwarded inline and leave the
+// accumulator as it was, and a panic in the aggregator is sent as an error
+// Result holding a PanicError.
+func Scan[T, U any](
+	input <-chan *Result[T],
+	initialValue U,
+	aggregator func(ctx context.Context, accumulator U, value T) (U, error),
+	opts ...Option,
+) <-chan *Result[U] {
+	options := newOptions(opts...)
+
+	return Stream(func(ctx context.Context, output chan<- *Result[U]) {
+		accumulator := initialValue
+		for result := range input {
+			err := result.Error
+			if err == nil {
+				var next U
+				next, err = protect(options.recoverPanics, func() (U, error) {
+					return aggregator(ctx, accumulator, result.Value)
+				})
+				if err == nil {
+					accumulator = next
+				}
+			}
+
+			if err != nil {
+				if !send(ctx, output, NewResult(*new(U), err)) {
+					go Drain(input)
+					return
+				}
+				continue
+			}
+			if !send(ctx, output, NewResult(accumulator, nil)) {
+				go Drain(input)
+				return
+			}
+		}
+	}, opts...)
+}
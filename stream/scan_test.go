@@ -0,0 +1,82 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScan(t *testing.T) {
+	sum := func(_ context.Context, total int, value int) (int, error) {
+		return total + value, nil
+	}
+
+	t.Run("running total", func(t *testing.T) {
+		data := Stream(func(ctx context.Context, output chan<- *Result[int]) {
+			output <- NewResult(1, nil)
+			output <- NewResult(2, nil)
+			output <- NewResult(3, nil)
+		})
+		totals := Scan(data, 10, sum)
+
+		require.Equal(t, NewResult(11, nil), <-totals)
+		require.Equal(t, NewResult(13, nil), <-totals)
+		require.Equal(t, NewResult(16, nil), <-totals)
+		validateChannel(t, nil, false, totals)
+	})
+
+	t.Run("matches Fold", func(t *testing.T) {
+		data := func() <-chan *Result[int] {
+			return Stream(func(ctx context.Context, output chan<- *Result[int]) {
+				for i := range 100 {
+					output <- NewResult(i, nil)
+				}
+			})
+		}
+
+		var last *Result[int]
+		for result := range Scan(data(), 0, sum) {
+			last = result
+		}
+		total, err := Fold(data(), 0, sum)
+		require.Equal(t, NewResult(total, err), last)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		errStream, errOdd := errors.New("stream"), errors.New("odd")
+		data := Stream(func(ctx context.Context, output chan<- *Result[int]) {
+			output <- NewResult(2, nil)
+			output <- NewResult(0, errStream)
+			output <- NewResult(3, nil)
+			output <- NewResult(4, nil)
+		})
+		totals := Scan(data, 0,
+			func(_ context.Context, total int, value int) (int, error) {
+				if value%2 == 1 {
+					return -1, errOdd
+				}
+				return total + value, nil
+			})
+
+		require.Equal(t, NewResult(2, nil), <-totals)
+		require.Equal(t, NewResult(0, errStream), <-totals)
+		require.Equal(t, NewResult(0, errOdd), <-totals)
+		require.Equal(t, NewResult(6, nil), <-totals)
+		validateChannel(t, nil, false, totals)
+	})
+
+	t.Run("cancelable", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		totals := Scan(counter(1000), 0, sum,
+			WithContext(ctx), WithBufferSize(0))
+		require.Equal(t, NewResult(0, nil), <-totals)
+		cancel()
+
+		requireNoLeaks(t, baseline)
+	})
+}
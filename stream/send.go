@@ -0,0 +1,21 @@
+package stream
+
+import "context"
+
+// send delivers value to output unless the context has been cancelled. it
+// reports whether the value was delivered.
+//
+// an already cancelled context is checked first so that a ready consumer can't
+// win the select and keep a cancelled stage running.
+func send[T any](ctx context.Context, output chan<- T, value T) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	select {
+	case <-ctx.Done():
+		return false
+	case output <- value:
+		return true
+	}
+}
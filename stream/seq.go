@@ -0,0 +1,61 @@
+package stream
+
+import (
+	"context"
+	"iter"
+)
+
+// FromSeq sends the values of an iterator as Results. iteration stops once the
+// context is cancelled.
+func FromSeq[T any](seq iter.Seq[T], opts ...Option) <-chan *Result[T] {
+	return Stream(func(ctx context.Context, output chan<- *Result[T]) {
+		for value := range seq {
+			if !send(ctx, output, NewResult(value, nil)) {
+				return
+			}
+		}
+	}, opts...)
+}
+
+// FromSeq2 sends the value and error pairs of an iterator as Results.
+// iteration stops once the context is cancelled.
+func FromSeq2[T any](seq iter.Seq2[T, error], opts ...Option) <-chan *Result[T] {
+	return Stream(func(ctx context.Context, output chan<- *Result[T]) {
+		for value, err := range seq {
+			if !send(ctx, output, NewResult(value, err)) {
+				return
+			}
+		}
+	}, opts...)
+}
+
+// ToSeq ranges over a channel as an iterator.
+//
+// cancel, which may be nil, should cancel the context of the pipeline feeding
+// input. it is called once iteration ends, and if the loop breaks early the
+// input is also drained in the background, so the upstream goroutines finish.
+func ToSeq[T any](input <-chan T, cancel context.CancelFunc) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		defer stopInput(input, cancel)
+
+		for value := range input {
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+// ToSeq2 ranges over a stream of Results as value and error pairs, ending as
+// ToSeq does.
+func ToSeq2[T any](input <-chan *Result[T], cancel context.CancelFunc) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		defer stopInput(input, cancel)
+
+		for result := range input {
+			if !yield(result.Value, result.Error) {
+				return
+			}
+		}
+	}
+}
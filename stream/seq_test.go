@@ -0,0 +1,90 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeq(t *testing.T) {
+	err := errors.New("generic error")
+
+	t.Run("FromSeq", func(t *testing.T) {
+		values := FromSeq(slices.Values([]int{1, 2, 3}))
+		require.Equal(t, NewResult(1, nil), <-values)
+		require.Equal(t, NewResult(2, nil), <-values)
+		require.Equal(t, NewResult(3, nil), <-values)
+		validateChannel(t, nil, false, values)
+	})
+
+	t.Run("FromSeq2", func(t *testing.T) {
+		values := FromSeq2(func(yield func(int, error) bool) {
+			_ = yield(1, nil) && yield(0, err) && yield(3, nil)
+		})
+		require.Equal(t, NewResult(1, nil), <-values)
+		require.Equal(t, NewResult(0, err), <-values)
+		require.Equal(t, NewResult(3, nil), <-values)
+		validateChannel(t, nil, false, values)
+	})
+
+	t.Run("FromSeq stops the iterator", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		stopped := make(chan struct{})
+		values := FromSeq(func(yield func(int) bool) {
+			defer close(stopped)
+			for i := 0; yield(i); i++ {
+			}
+		}, WithContext(ctx), WithBufferSize(0))
+
+		require.Equal(t, NewResult(0, nil), <-values)
+		cancel()
+		<-stopped
+	})
+
+	t.Run("ToSeq", func(t *testing.T) {
+		input := make(chan int, 3)
+		input <- 0
+		input <- 1
+		input <- 2
+		close(input)
+		require.Equal(t, []int{0, 1, 2}, slices.Collect(ToSeq(input, nil)))
+	})
+
+	t.Run("ToSeq2", func(t *testing.T) {
+		values := FromSeq2(func(yield func(int, error) bool) {
+			_ = yield(1, nil) && yield(0, err)
+		})
+
+		var seen []int
+		var errs []error
+		for value, err := range ToSeq2(values, nil) {
+			seen = append(seen, value)
+			errs = append(errs, err)
+		}
+		require.Equal(t, []int{1, 0}, seen)
+		require.Equal(t, []error{nil, err}, errs)
+	})
+
+	t.Run("break cancels and drains", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		doubled := Transform(counter(1000),
+			func(_ context.Context, value int) (int, error) {
+				return value * 2, nil
+			}, WithContext(ctx), WithBufferSize(0))
+		for value, err := range ToSeq2(doubled, cancel) {
+			require.NoError(t, err)
+			if value == 4 {
+				break
+			}
+		}
+		require.Error(t, ctx.Err())
+
+		requireNoLeaks(t, baseline)
+	})
+}
@@ -0,0 +1,106 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// FromSlice sends the values of a slice.
+func FromSlice[T any](values []T, opts ...Option) <-chan *Result[T] {
+	return Stream(func(ctx context.Context, output chan<- *Result[T]) {
+		for _, value := range values {
+			if !send(ctx, output, NewResult(value, nil)) {
+				return
+			}
+		}
+	}, opts...)
+}
+
+// FromMap sends the entries of a map as key and value Pairs, in the map's
+// iteration order.
+func FromMap[K comparable, V any](values map[K]V, opts ...Option) <-chan *Result[Pair[K, V]] {
+	return Stream(func(ctx context.Context, output chan<- *Result[Pair[K, V]]) {
+		for key, value := range values {
+			if !send(ctx, output, NewResult(Pair[K, V]{key, value}, nil)) {
+				return
+			}
+		}
+	}, opts...)
+}
+
+// Range sends the integers from start up to, but not including, end.
+func Range(start, end int, opts ...Option) <-chan *Result[int] {
+	return Stream(func(ctx context.Context, output chan<- *Result[int]) {
+		for i := start; i < end; i++ {
+			if !send(ctx, output, NewResult(i, nil)) {
+				return
+			}
+		}
+	}, opts...)
+}
+
+// Repeat sends the value until the context is cancelled.
+//
+// like the other endless sources, it must be stopped through its context, such
+// as by passing its cancel function to Take, since draining it never finishes.
+func Repeat[T any](value T, opts ...Option) <-chan *Result[T] {
+	return Stream(func(ctx context.Context, output chan<- *Result[T]) {
+		for send(ctx, output, NewResult(value, nil)) {
+		}
+	}, opts...)
+}
+
+// Generate sends the Results of calling fn repeatedly, until it returns io.EOF
+// or the context is cancelled. the io.EOF itself is not sent.
+func Generate[T any](fn func(ctx context.Context) (T, error), opts ...Option) <-chan *Result[T] {
+	return Stream(func(ctx context.Context, output chan<- *Result[T]) {
+		for {
+			value, err := fn(ctx)
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if !send(ctx, output, NewResult(value, err)) {
+				return
+			}
+		}
+	}, opts...)
+}
+
+// Ticker sends the time every interval, as read from the WithClock Clock,
+// until the context is cancelled.
+//
+// the next tick is only scheduled once the last has been sent, so a slow
+// consumer delays the ticks rather than letting them pile up.
+func Ticker(interval time.Duration, opts ...Option) <-chan *Result[time.Time] {
+	clock := newOptions(opts...).clock
+
+	return Stream(func(ctx context.Context, output chan<- *Result[time.Time]) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-clock.After(interval):
+				if !send(ctx, output, NewResult(now, nil)) {
+					return
+				}
+			}
+		}
+	}, opts...)
+}
+
+// FromChannel wraps the values of a plain channel as Results.
+//
+// If the context is cancelled, the output is closed and the input is drained
+// in the background.
+func FromChannel[T any](input <-chan T, opts ...Option) <-chan *Result[T] {
+	return Stream(func(ctx context.Context, output chan<- *Result[T]) {
+		for value := range input {
+			if !send(ctx, output, NewResult(value, nil)) {
+				go Drain(input)
+				return
+			}
+		}
+	}, opts...)
+}
@@ -0,0 +1,117 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"io"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSources(t *testing.T) {
+	t.Run("FromSlice", func(t *testing.T) {
+		values := FromSlice([]string{"a", "b"})
+		require.Equal(t, NewResult("a", nil), <-values)
+		require.Equal(t, NewResult("b", nil), <-values)
+		validateChannel(t, nil, false, values)
+	})
+
+	t.Run("FromMap", func(t *testing.T) {
+		entries := map[string]int{}
+		for result := range FromMap(map[string]int{"a": 1, "b": 2}) {
+			require.NoError(t, result.Error)
+			entries[result.Value.Left] = result.Value.Right
+		}
+		require.Equal(t, map[string]int{"a": 1, "b": 2}, entries)
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		values := Range(3, 6)
+		require.Equal(t, NewResult(3, nil), <-values)
+		require.Equal(t, NewResult(4, nil), <-values)
+		require.Equal(t, NewResult(5, nil), <-values)
+		validateChannel(t, nil, false, values)
+
+		validateChannel(t, nil, false, Range(6, 3))
+	})
+
+	t.Run("Repeat", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		values := Repeat("a", WithContext(ctx), WithBufferSize(0))
+		require.Equal(t, NewResult("a", nil), <-values)
+		require.Equal(t, NewResult("a", nil), <-values)
+		cancel()
+
+		requireNoLeaks(t, baseline)
+	})
+
+	t.Run("Generate", func(t *testing.T) {
+		err := errors.New("generic error")
+		i := 0
+		values := Generate(func(context.Context) (int, error) {
+			i++
+			switch i {
+			case 2:
+				return 0, err
+			case 4:
+				return 0, io.EOF
+			}
+			return i, nil
+		})
+		require.Equal(t, NewResult(1, nil), <-values)
+		require.Equal(t, NewResult(0, err), <-values)
+		require.Equal(t, NewResult(3, nil), <-values)
+		validateChannel(t, nil, false, values)
+	})
+
+	t.Run("Ticker", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+		clock := newFakeClock()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		ticks := Ticker(time.Second, WithClock(clock), WithContext(ctx), WithBufferSize(0))
+		for i := range 3 {
+			clock.WaitForTimers(t, 1)
+			clock.Advance(time.Second)
+			require.Equal(t, NewResult(time.Unix(int64(i+1), 0), nil), <-ticks)
+		}
+		cancel()
+
+		requireNoLeaks(t, baseline)
+	})
+
+	t.Run("FromChannel", func(t *testing.T) {
+		input := make(chan int, 2)
+		input <- 1
+		input <- 2
+		close(input)
+
+		values := FromChannel(input)
+		require.Equal(t, NewResult(1, nil), <-values)
+		require.Equal(t, NewResult(2, nil), <-values)
+		validateChannel(t, nil, false, values)
+	})
+
+	t.Run("FromChannel drains on cancel", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		input := make(chan int)
+		go func() {
+			defer close(input)
+			for i := range 1000 {
+				input <- i
+			}
+		}()
+		values := FromChannel(input, WithContext(ctx), WithBufferSize(0))
+		require.Equal(t, NewResult(0, nil), <-values)
+		cancel()
+
+		requireNoLeaks(t, baseline)
+	})
+}
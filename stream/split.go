@@ -0,0 +1,35 @@
+package stream
+
+// SplitErrors separates a Result stream into a channel of valid values and a
+// channel of errors, so good values can be processed while the bad ones are
+// logged.
+//
+// NOTE: as with Tee, both channels must be read or the slower consumer will
+// govern the overall throughput.
+func SplitErrors[T any](
+	input <-chan *Result[T],
+	opts ...Option,
+) (<-chan T, <-chan error) {
+	options := newOptions(opts...)
+
+	values := make(chan T, options.bufferSize)
+	errs := make(chan error, options.bufferSize)
+	go func() {
+		defer close(values)
+		defer close(errs)
+		for result := range input {
+			var ok bool
+			if result.Error != nil {
+				ok = send(options.ctx, errs, result.Error)
+			} else {
+				ok = send(options.ctx, values, result.Value)
+			}
+			if !ok {
+				go Drain(input)
+				return
+			}
+		}
+	}()
+
+	return values, errs
+}
@@ -0,0 +1,46 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitErrors(t *testing.T) {
+	t.Run("simple", func(t *testing.T) {
+		err1, err2 := errors.New("first"), errors.New("second")
+		src := Stream(func(_ context.Context, output chan<- *Result[int]) {
+			output <- NewResult(1, nil)
+			output <- NewResult(0, err1)
+			output <- NewResult(2, nil)
+			output <- NewResult(0, err2)
+			output <- NewResult(3, nil)
+		})
+		values, errs := SplitErrors(src)
+
+		actual := []int{}
+		for value := range values {
+			actual = append(actual, value)
+		}
+		require.Equal(t, []int{1, 2, 3}, actual)
+
+		require.Equal(t, err1, <-errs)
+		require.Equal(t, err2, <-errs)
+		validateChannel(t, nil, false, errs)
+	})
+
+	t.Run("cancelable", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		values, _ := SplitErrors(counter(1000),
+			WithContext(ctx), WithBufferSize(0))
+		require.Equal(t, 0, <-values)
+		cancel()
+
+		requireNoLeaks(t, baseline)
+	})
+}
@@ -4,6 +4,9 @@ import "context"
 
 // Spread is a pipeline "flattener". provide it a channel of slices of data and
 // it will return a channel of individual items.
+//
+// If the context is cancelled, the remainder of the current slice is dropped
+// and the input is drained in the background.
 func Spread[T any](
 	input <-chan *Result[[]T],
 	opts ...Option,
@@ -11,10 +14,16 @@ func Spread[T any](
 	return Stream(func(ctx context.Context, output chan<- *Result[T]) {
 		for results := range input {
 			if results.Error != nil {
-				output <- NewResult(*new(T), results.Error)
-			} else {
-				for _, item := range results.Value {
-					output <- NewResult(item, nil)
+				if !send(ctx, output, NewResult(*new(T), results.Error)) {
+					go Drain(input)
+					return
+				}
+				continue
+			}
+			for _, item := range results.Value {
+				if !send(ctx, output, NewResult(item, nil)) {
+					go Drain(input)
+					return
 				}
 			}
 		}
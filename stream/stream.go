@@ -16,23 +16,26 @@ import "context"
 // when the provided function completes, the output channel is closed and
 // consumers can drain the channel as normal.
 //
+// if the function panics on a channel of Results, the panic is sent as a final
+// error Result holding a PanicError before the channel is closed. panics on
+// other channels continue once the channel is closed. see WithPanicRecovery.
+// the stages of this package drain their inputs however they end, so a
+// recovered panic never leaves their producers blocked.
+//
 // See the tests for examples and benchmarks.
 func Stream[T any](
 	src func(ctx context.Context, output chan<- T),
 	opts ...Option,
 ) <-chan T {
-	options := &options{
-		ctx:        context.Background(),
-		bufferSize: defaultBufferSize,
-	}
-	for _, opt := range opts {
-		opt(options)
-	}
+	options := newOptions(opts...)
 
 	output := make(chan T, options.bufferSize)
 
 	go func() {
 		defer close(output)
+		if options.recoverPanics {
+			defer recoverResult(options.ctx, output)
+		}
 		src(options.ctx, output)
 	}()
 
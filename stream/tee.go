@@ -1,9 +1,5 @@
 package stream
 
-import (
-	"context"
-)
-
 // Tee copies messages to two output channels.
 // It only does a simple copy, so pointers, and nested pointers, will
 // both reference the same original memory.
@@ -13,15 +9,12 @@ import (
 // process - source, target 1, or target 2, may govern the overall throughput.
 // Set buffersizes to match expectations.
 //
+// If the context is cancelled, both outputs are closed, even if a message was
+// only delivered to the first, and the input is drained in the background.
+//
 // Trivia: Tee refers to a 90degree split in pipes, as incorporated into Linux.
 func Tee[T any](input <-chan T, opts ...Option) (<-chan T, <-chan T) {
-	options := &options{
-		ctx:        context.Background(),
-		bufferSize: defaultBufferSize,
-	}
-	for _, opt := range opts {
-		opt(options)
-	}
+	options := newOptions(opts...)
 
 	out1 := make(chan T, options.bufferSize)
 	out2 := make(chan T, options.bufferSize)
@@ -29,14 +22,10 @@ func Tee[T any](input <-chan T, opts ...Option) (<-chan T, <-chan T) {
 		defer close(out1)
 		defer close(out2)
 		for msg := range input {
-			select {
-			case <-options.ctx.Done():
+			if !send(options.ctx, out1, msg) || !send(options.ctx, out2, msg) {
+				go Drain(input)
 				return
-			default:
-				out1 <- msg
-				out2 <- msg
 			}
-
 		}
 	}()
 
@@ -0,0 +1,130 @@
+package stream
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter is a token bucket rate limiter. it is safe for concurrent use, so a
+// single limiter can hold every worker of a Processor under a shared rate. see
+// Limit and Throttle.
+type Limiter struct {
+	clock Clock
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter creates a Limiter that allows rate events per second on average,
+// with bursts of up to burst events. the bucket starts full.
+//
+// a rate of zero or less never refills the bucket: once the burst is spent,
+// Wait blocks until its context is cancelled.
+//
+// note: only the WithClock option has any effect.
+func NewLimiter(rate float64, burst int, opts ...Option) *Limiter {
+	clock := newOptions(opts...).clock
+	burst = max(burst, 1)
+
+	return &Limiter{
+		clock:  clock,
+		rate:   max(rate, 0),
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   clock.Now(),
+	}
+}
+
+// Wait blocks until an event is allowed or the context is cancelled, in which
+// case the context's error is returned.
+func (limiter *Limiter) Wait(ctx context.Context) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	delay, ok := limiter.reserve()
+	if ok && delay <= 0 {
+		return nil
+	}
+
+	// a token that never comes leaves the nil channel to block forever.
+	var ready <-chan time.Time
+	if ok {
+		ready = limiter.clock.After(delay)
+	}
+	select {
+	case <-ctx.Done():
+		limiter.cancel()
+		return ctx.Err()
+	case <-ready:
+		return nil
+	}
+}
+
+// reserve takes a token, possibly going into debt, and returns how long the
+// caller must wait for it. it reports false when the token will never come.
+func (limiter *Limiter) reserve() (time.Duration, bool) {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	now := limiter.clock.Now()
+	elapsed := now.Sub(limiter.last).Seconds()
+	limiter.tokens = min(limiter.burst, limiter.tokens+elapsed*limiter.rate)
+	limiter.last = now
+
+	limiter.tokens--
+	if limiter.tokens >= 0 {
+		return 0, true
+	}
+	if limiter.rate == 0 {
+		return 0, false
+	}
+	return time.Duration(-limiter.tokens / limiter.rate * float64(time.Second)), true
+}
+
+// cancel returns the token of an abandoned reservation.
+func (limiter *Limiter) cancel() {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	limiter.tokens = min(limiter.burst, limiter.tokens+1)
+}
+
+// Limit wraps a Transform function so that every call waits on the limiter. a
+// cancelled wait returns the context's error without calling the function.
+func Limit[T, U any](
+	limiter *Limiter,
+	transform func(ctx context.Context, input T) (U, error),
+) func(ctx context.Context, input T) (U, error) {
+	return func(ctx context.Context, input T) (U, error) {
+		if err := limiter.Wait(ctx); err != nil {
+			return *new(U), err
+		}
+		return transform(ctx, input)
+	}
+}
+
+// Throttle passes values through at no more than rate values per second, with
+// bursts of up to burst values. as with NewLimiter, a rate of zero or less
+// passes only the burst, then holds the stream until the context is cancelled.
+func Throttle[T any](
+	input <-chan T,
+	rate float64,
+	burst int,
+	opts ...Option,
+) <-chan T {
+	limiter := NewLimiter(rate, burst, opts...)
+
+	return Stream(func(ctx context.Context, output chan<- T) {
+		for value := range input {
+			if limiter.Wait(ctx) != nil || !send(ctx, output, value) {
+				go Drain(input)
+				return
+			}
+		}
+	}, opts...)
+}
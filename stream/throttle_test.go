@@ -0,0 +1,140 @@
+package stream
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	t.Run("burst then rate", func(t *testing.T) {
+		clock := newFakeClock()
+		limiter := NewLimiter(2, 3, WithClock(clock))
+
+		// the bucket starts full.
+		for range 3 {
+			require.NoError(t, limiter.Wait(context.Background()))
+		}
+		require.Empty(t, clock.waiters)
+
+		// then refills at half a second per token.
+		done := make(chan error)
+		go func() {
+			done <- limiter.Wait(context.Background())
+		}()
+		clock.WaitForTimers(t, 1)
+		clock.Advance(500 * time.Millisecond)
+		require.NoError(t, <-done)
+
+		clock.Advance(time.Second)
+		require.NoError(t, limiter.Wait(context.Background()))
+		require.NoError(t, limiter.Wait(context.Background()))
+	})
+
+	t.Run("cancelable", func(t *testing.T) {
+		clock := newFakeClock()
+		limiter := NewLimiter(1, 1, WithClock(clock))
+		require.NoError(t, limiter.Wait(context.Background()))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(time.Millisecond, cancel)
+		require.ErrorIs(t, limiter.Wait(ctx), context.Canceled)
+
+		// the abandoned reservation is returned.
+		clock.Advance(time.Second)
+		require.NoError(t, limiter.Wait(context.Background()))
+	})
+
+	t.Run("zero rate", func(t *testing.T) {
+		clock := newFakeClock()
+		limiter := NewLimiter(0, 2, WithClock(clock))
+		require.NoError(t, limiter.Wait(context.Background()))
+		require.NoError(t, limiter.Wait(context.Background()))
+
+		// the bucket never refills, so the next wait only ends when cancelled.
+		clock.Advance(time.Hour)
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(time.Millisecond, cancel)
+		require.ErrorIs(t, limiter.Wait(ctx), context.Canceled)
+		require.Empty(t, clock.waiters)
+	})
+
+	t.Run("shared by Limit", func(t *testing.T) {
+		limiter := NewLimiter(1000, 1)
+		identity := Limit(limiter,
+			func(_ context.Context, input int) (int, error) {
+				return input, nil
+			})
+
+		start := time.Now()
+		var wg sync.WaitGroup
+		for range 4 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range 25 {
+					_, err := identity(context.Background(), i)
+					assert.NoError(t, err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		// 100 calls at 1000/s across all workers, less the initial token.
+		require.GreaterOrEqual(t, time.Since(start), 99*time.Millisecond)
+	})
+}
+
+func TestThrottle(t *testing.T) {
+	t.Run("rate", func(t *testing.T) {
+		src := Stream(func(_ context.Context, output chan<- int) {
+			for i := range 10 {
+				output <- i
+			}
+		})
+
+		start := time.Now()
+		actual := []int{}
+		for value := range Throttle(src, 200, 1) {
+			actual = append(actual, value)
+		}
+
+		require.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, actual)
+		require.GreaterOrEqual(t, time.Since(start), 45*time.Millisecond)
+	})
+
+	t.Run("cancelable", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		throttled := Throttle(counter(1000), 1, 1,
+			WithContext(ctx), WithBufferSize(0))
+		require.Equal(t, NewResult(0, nil), <-throttled)
+		cancel()
+
+		requireNoLeaks(t, baseline)
+	})
+
+	t.Run("zero rate holds after the burst", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		throttled := Throttle(counter(1000), 0, 2,
+			WithContext(ctx), WithBufferSize(0))
+		require.Equal(t, NewResult(0, nil), <-throttled)
+		require.Equal(t, NewResult(1, nil), <-throttled)
+		select {
+		case <-throttled:
+			t.Fatal("the burst was exceeded")
+		case <-time.After(10 * time.Millisecond):
+		}
+		cancel()
+
+		requireNoLeaks(t, baseline)
+	})
+}
@@ -1,21 +1,85 @@
 package stream
 
 import (
-	"errors"
 	"fmt"
 	"runtime"
+	"slices"
+	"strings"
 )
 
-// Trace wraps an incoming error with an error noting the source of the error or
-// returns the unmodified input value.
+// Frame is a location noted by Trace.
+type Frame struct {
+	File     string
+	Line     int
+	Function string
+	// Stage is the optional name given to TraceStage.
+	Stage string
+}
+
+func (frame Frame) String() string {
+	stage := ""
+	if frame.Stage != "" {
+		stage = " [" + frame.Stage + "]"
+	}
+	return fmt.Sprintf("stream.Trace%s - %s:%d (%s)",
+		stage, frame.File, frame.Line, frame.Function)
+}
+
+// TraceError is an error annotated with the locations it was traced through,
+// in the order they were noted. retrieve it with errors.As.
+type TraceError struct {
+	Err    error
+	Frames []Frame
+}
+
+// Error renders the original error followed by the chain of locations.
+func (err *TraceError) Error() string {
+	var builder strings.Builder
+	builder.WriteString(err.Err.Error())
+	for _, frame := range err.Frames {
+		builder.WriteString("\n")
+		builder.WriteString(frame.String())
+	}
+	return builder.String()
+}
+
+func (err *TraceError) Unwrap() error {
+	return err.Err
+}
+
+// Trace wraps an incoming error with a TraceError noting the source of the
+// error or returns the unmodified input value.
+//
+// an error that has already been traced gains another frame, so an error
+// passed along through several traced functions records its whole path.
 func Trace[T any](value T, err error) (T, error) {
 	if err != nil {
-		_, file, line, _ := runtime.Caller(1)
-		return value, errors.Join(
-			err,
-			fmt.Errorf("stream.Trace - %s:%d", file, line),
-		)
+		return value, trace(err, "")
 	}
 
 	return value, nil
 }
+
+// TraceStage is a Trace that also names the stage the error passed through.
+func TraceStage[T any](stage string, value T, err error) (T, error) {
+	if err != nil {
+		return value, trace(err, stage)
+	}
+
+	return value, nil
+}
+
+// trace notes the location of the caller of Trace or TraceStage.
+func trace(err error, stage string) error {
+	pc, file, line, _ := runtime.Caller(2)
+	frame := Frame{File: file, Line: line, Stage: stage}
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		frame.Function = fn.Name()
+	}
+
+	if traced, ok := err.(*TraceError); ok {
+		frames := append(slices.Clip(traced.Frames), frame)
+		return &TraceError{Err: traced.Err, Frames: frames}
+	}
+	return &TraceError{Err: err, Frames: []Frame{frame}}
+}
@@ -0,0 +1,77 @@
+package stream
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrace(t *testing.T) {
+	errBase := errors.New("base")
+
+	t.Run("no error", func(t *testing.T) {
+		value, err := Trace(1, nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, value)
+	})
+
+	t.Run("single frame", func(t *testing.T) {
+		value, err := Trace(1, errBase)
+		require.Equal(t, 1, value)
+		require.ErrorIs(t, err, errBase)
+
+		var traced *TraceError
+		require.ErrorAs(t, err, &traced)
+		require.Len(t, traced.Frames, 1)
+		require.Contains(t, traced.Frames[0].File, "trace_test.go")
+		require.Positive(t, traced.Frames[0].Line)
+		require.Contains(t, traced.Frames[0].Function, "TestTrace")
+		require.Empty(t, traced.Frames[0].Stage)
+	})
+
+	t.Run("accumulates frames", func(t *testing.T) {
+		inner := func() (int, error) {
+			return TraceStage("inner", 0, errBase)
+		}
+		outer := func() (int, error) {
+			return Trace(inner())
+		}
+		_, err := outer()
+
+		var traced *TraceError
+		require.ErrorAs(t, err, &traced)
+		require.Equal(t, errBase, traced.Err)
+		require.Len(t, traced.Frames, 2)
+		require.Equal(t, "inner", traced.Frames[0].Stage)
+		require.NotEqual(t, traced.Frames[0].Line, traced.Frames[1].Line)
+
+		require.Equal(t,
+			"base\n"+traced.Frames[0].String()+"\n"+traced.Frames[1].String(),
+			err.Error(),
+		)
+		require.Equal(t,
+			fmt.Sprintf("stream.Trace [inner] - %s:%d (%s)",
+				traced.Frames[0].File,
+				traced.Frames[0].Line,
+				traced.Frames[0].Function,
+			),
+			traced.Frames[0].String(),
+		)
+	})
+
+	t.Run("wrapped", func(t *testing.T) {
+		_, err := Trace(os.Open("testfiles/example_nonexistant.gz"))
+		_, err = Trace(0, fmt.Errorf("opening: %w", err))
+
+		// the outer trace wraps the annotated error rather than extending it.
+		var traced *TraceError
+		require.ErrorAs(t, err, &traced)
+		require.Len(t, traced.Frames, 1)
+		require.ErrorAs(t, traced.Err, &traced)
+		require.Len(t, traced.Frames, 1)
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
@@ -7,17 +7,35 @@ import "context"
 //
 // This model, combined with basic channels as the interface, allows simple
 // composition of functions as demonstrated in the test cases.
+//
+// If the context is cancelled, Transform stops sending, drains the input in
+// the background, and closes its output.
+//
+// WithDeadLetters keeps a copy of the inputs the transform function fails on.
+// a panic in the transform function is sent as an error Result holding a
+// PanicError, and the stream carries on with the next value.
 func Transform[T, U any](
 	input <-chan *Result[T],
 	transform func(ctx context.Context, input T) (U, error),
 	opts ...Option,
 ) <-chan *Result[U] {
+	options := newOptions(opts...)
+
 	return Stream(func(ctx context.Context, output chan<- *Result[U]) {
 		for result := range input {
+			var next *Result[U]
 			if result.Error != nil {
-				output <- NewResult(*new(U), result.Error)
+				next = NewResult(*new(U), result.Error)
 			} else {
-				output <- NewResult(transform(ctx, result.Value))
+				value, err := protect(options.recoverPanics, func() (U, error) {
+					return transform(ctx, result.Value)
+				})
+				err = deadLetter(options.deadLetters, result.Value, err)
+				next = NewResult(value, err)
+			}
+			if !send(ctx, output, next) {
+				go Drain(input)
+				return
 			}
 		}
 	}, opts...)
@@ -0,0 +1,98 @@
+package stream
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Timestamped is a value with its event time and the watermark of the stream
+// when it was sent.
+type Timestamped[T any] struct {
+	Value     T
+	Time      time.Time
+	Watermark time.Time
+}
+
+// Watermark is a Multiplex that tracks event time across its inputs.
+//
+// the watermark is the earliest of the latest event times seen on each open
+// input, so it only advances once every input has moved past it. it never
+// moves backwards, and a closed input no longer holds it back. values are sent
+// with their event time and the watermark at that moment, ready for
+// EventTimeWindow.
+//
+// a panic in eventTime is sent as an error Result holding a PanicError, and
+// the value is skipped.
+//
+// note: the watermark doesn't advance until every input has sent a value.
+func Watermark[T any](
+	inputs []<-chan *Result[T],
+	eventTime func(value T) time.Time,
+	opts ...Option,
+) <-chan *Result[Timestamped[T]] {
+	options := newOptions(opts...)
+
+	return Stream(func(ctx context.Context, output chan<- *Result[Timestamped[T]]) {
+		var mu sync.Mutex
+		latest := make([]time.Time, len(inputs))
+		done := make([]bool, len(inputs))
+		var watermark time.Time
+
+		// advance records the latest event time of an input, or that it has
+		// closed, and returns the resulting watermark.
+		advance := func(id int, at time.Time, closed bool) time.Time {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if closed {
+				done[id] = true
+			} else if at.After(latest[id]) {
+				latest[id] = at
+			}
+
+			var earliest time.Time
+			found := false
+			for i := range inputs {
+				if !done[i] && (!found || latest[i].Before(earliest)) {
+					earliest, found = latest[i], true
+				}
+			}
+			if found && earliest.After(watermark) {
+				watermark = earliest
+			}
+
+			return watermark
+		}
+
+		var wg sync.WaitGroup
+		wg.Add(len(inputs))
+		for id, input := range inputs {
+			go func() {
+				defer wg.Done()
+				defer advance(id, time.Time{}, true)
+				for result := range input {
+					next := NewResult(Timestamped[T]{}, result.Error)
+					if result.Error == nil {
+						at, err := protect(options.recoverPanics, func() (time.Time, error) {
+							return eventTime(result.Value), nil
+						})
+						next.Error = err
+						if err == nil {
+							next.Value = Timestamped[T]{
+								Value:     result.Value,
+								Time:      at,
+								Watermark: advance(id, at, false),
+							}
+						}
+					}
+					if !send(ctx, output, next) {
+						go Drain(input)
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+	}, opts...)
+}
@@ -0,0 +1,88 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatermark(t *testing.T) {
+	timestamped := func(value event, watermark int) *Result[Timestamped[event]] {
+		return NewResult(Timestamped[event]{
+			Value:     value,
+			Time:      eventTime(value),
+			Watermark: time.Unix(int64(watermark), 0),
+		}, nil)
+	}
+
+	t.Run("minimum across inputs", func(t *testing.T) {
+		first := make(chan *Result[event])
+		second := make(chan *Result[event])
+		defer close(first)
+		marked := Watermark([]<-chan *Result[event]{first, second}, eventTime)
+
+		// the second input hasn't sent anything, so the watermark can't move.
+		first <- NewResult(event{"a", 1}, nil)
+		require.Equal(t, NewResult(Timestamped[event]{
+			Value: event{"a", 1},
+			Time:  time.Unix(1, 0),
+		}, nil), <-marked)
+
+		second <- NewResult(event{"b", 2}, nil)
+		require.Equal(t, timestamped(event{"b", 2}, 1), <-marked)
+
+		first <- NewResult(event{"c", 5}, nil)
+		require.Equal(t, timestamped(event{"c", 5}, 2), <-marked)
+
+		// out of order values don't move the watermark backwards.
+		first <- NewResult(event{"d", 0}, nil)
+		require.Equal(t, timestamped(event{"d", 0}, 2), <-marked)
+
+		// a closed input no longer holds the watermark back. the close is seen
+		// at some point after it happens, and until then the watermark holds.
+		close(second)
+		for {
+			first <- NewResult(event{"e", 6}, nil)
+			result := <-marked
+			if result.Value.Watermark.Equal(time.Unix(2, 0)) {
+				continue
+			}
+			require.Equal(t, timestamped(event{"e", 6}, 6), result)
+			break
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		err := errors.New("generic error")
+		src := Stream(func(_ context.Context, output chan<- *Result[event]) {
+			output <- NewResult(event{}, err)
+			output <- NewResult(event{"a", 1}, nil)
+		})
+		marked := Watermark([]<-chan *Result[event]{src}, eventTime)
+
+		require.Equal(t, NewResult(Timestamped[event]{}, err), <-marked)
+		require.Equal(t, timestamped(event{"a", 1}, 1), <-marked)
+		validateChannel(t, nil, false, marked)
+	})
+
+	t.Run("cancelable", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		marked := Watermark(
+			[]<-chan *Result[int]{counter(1000), counter(1000)},
+			func(value int) time.Time {
+				return time.Unix(int64(value), 0)
+			},
+			WithContext(ctx), WithBufferSize(0),
+		)
+		<-marked
+		cancel()
+
+		requireNoLeaks(t, baseline)
+	})
+}
@@ -0,0 +1,360 @@
+package stream
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// Window is a group of values whose timestamps fall within [Start, End).
+type Window[T any] struct {
+	Start time.Time
+	End   time.Time
+	Items []T
+}
+
+// Windowing describes how values are grouped into windows. see Tumbling,
+// Sliding and Session.
+type Windowing struct {
+	size  time.Duration
+	slide time.Duration
+	gap   time.Duration
+}
+
+// Tumbling describes back-to-back windows of a fixed size, aligned to
+// multiples of size.
+func Tumbling(size time.Duration) Windowing {
+	return Sliding(size, size)
+}
+
+// Sliding describes overlapping windows of a fixed size that start every
+// slide, so a value may belong to several windows.
+func Sliding(size, slide time.Duration) Windowing {
+	if size <= 0 || slide <= 0 {
+		panic("stream: non-positive window size or slide")
+	}
+
+	return Windowing{size: size, slide: slide}
+}
+
+// Session describes sessions of activity. a session lasts until no value has
+// been seen for gap, so its End is the last timestamp plus gap.
+func Session(gap time.Duration) Windowing {
+	if gap <= 0 {
+		panic("stream: non-positive session gap")
+	}
+
+	return Windowing{gap: gap}
+}
+
+// TumblingWindow groups values into Tumbling windows.
+//
+// eventTime extracts the timestamp of a value. if it is nil, values are stamped
+// with the time they are received, and windows are sent as the clock passes
+// their end. with event time, a window is sent once a value with a later
+// timestamp arrives; a value that belongs only to windows already sent is late
+// and is dropped. use Watermark and EventTimeWindow for more control over
+// late values.
+//
+// error Results are forwarded immediately and any open windows are sent when
+// the input closes.
+func TumblingWindow[T any](
+	input <-chan *Result[T],
+	size time.Duration,
+	eventTime func(value T) time.Time,
+	opts ...Option,
+) <-chan *Result[Window[T]] {
+	return timeWindow(input, Tumbling(size), eventTime, opts...)
+}
+
+// SlidingWindow groups values into Sliding windows.
+//
+// see TumblingWindow for the handling of time, errors and late values.
+func SlidingWindow[T any](
+	input <-chan *Result[T],
+	size time.Duration,
+	slide time.Duration,
+	eventTime func(value T) time.Time,
+	opts ...Option,
+) <-chan *Result[Window[T]] {
+	return timeWindow(input, Sliding(size, slide), eventTime, opts...)
+}
+
+// SessionWindow groups values into Session windows.
+//
+// see TumblingWindow for the handling of time, errors and late values.
+func SessionWindow[T any](
+	input <-chan *Result[T],
+	gap time.Duration,
+	eventTime func(value T) time.Time,
+	opts ...Option,
+) <-chan *Result[Window[T]] {
+	return timeWindow(input, Session(gap), eventTime, opts...)
+}
+
+// EventTimeWindow groups Watermark output into windows by event time.
+//
+// a window is sent once the watermark passes its end plus allowedLateness, so
+// values that arrive out of order within that allowance still join their
+// window. values that belong only to windows already sent are late, and are
+// sent on the second channel rather than being dropped.
+//
+// note: both channels must be read, or the stage will block once the late
+// channel's buffer is full.
+func EventTimeWindow[T any](
+	input <-chan *Result[Timestamped[T]],
+	windowing Windowing,
+	allowedLateness time.Duration,
+	opts ...Option,
+) (<-chan *Result[Window[T]], <-chan T) {
+	late := make(chan T, newOptions(opts...).bufferSize)
+
+	windows := windowStream(input, windowConfig[Timestamped[T], T]{
+		assign: windowAssignerFor[T](windowing),
+		stamp: func(input Timestamped[T]) (T, time.Time, time.Time) {
+			return input.Value, input.Time, input.Watermark
+		},
+		lateness: allowedLateness,
+		late:     late,
+	}, opts...)
+
+	return windows, late
+}
+
+// timeWindow adapts an eventTime function, or its absence, to the window
+// engine.
+func timeWindow[T any](
+	input <-chan *Result[T],
+	windowing Windowing,
+	eventTime func(value T) time.Time,
+	opts ...Option,
+) <-chan *Result[Window[T]] {
+	config := windowConfig[T, T]{
+		assign:     windowAssignerFor[T](windowing),
+		processing: eventTime == nil,
+		stamp: func(input T) (T, time.Time, time.Time) {
+			return input, time.Time{}, time.Time{}
+		},
+	}
+	if eventTime != nil {
+		config.stamp = func(input T) (T, time.Time, time.Time) {
+			at := eventTime(input)
+			return input, at, at
+		}
+	}
+
+	return windowStream(input, config, opts...)
+}
+
+// windowAssigner places a value stamped at into the open windows and returns
+// the updated set. windows that end at or before the watermark have already
+// been sent, so they are never created; the bool reports whether the value was
+// placed at all.
+type windowAssigner[T any] func(
+	open []*Window[T],
+	at time.Time,
+	watermark time.Time,
+	value T,
+) ([]*Window[T], bool)
+
+func windowAssignerFor[T any](windowing Windowing) windowAssigner[T] {
+	switch {
+	case windowing.gap > 0:
+		return sessionAssigner[T](windowing.gap)
+	case windowing.size > 0:
+		return slidingAssigner[T](windowing.size, windowing.slide)
+	default:
+		panic("stream: empty Windowing")
+	}
+}
+
+func slidingAssigner[T any](size, slide time.Duration) windowAssigner[T] {
+	return func(
+		open []*Window[T],
+		at time.Time,
+		watermark time.Time,
+		value T,
+	) ([]*Window[T], bool) {
+		placed := false
+		// the latest window holding the value starts at the slide boundary, the
+		// earlier ones overlap it and end sooner.
+		for start := at.Truncate(slide); start.Add(size).After(at); start = start.Add(-slide) {
+			end := start.Add(size)
+			if !end.After(watermark) {
+				break
+			}
+			placed = true
+
+			index := slices.IndexFunc(open, func(window *Window[T]) bool {
+				return window.Start.Equal(start)
+			})
+			if index < 0 {
+				open = append(open, &Window[T]{Start: start, End: end})
+				index = len(open) - 1
+			}
+			open[index].Items = append(open[index].Items, value)
+		}
+
+		return open, placed
+	}
+}
+
+func sessionAssigner[T any](gap time.Duration) windowAssigner[T] {
+	return func(
+		open []*Window[T],
+		at time.Time,
+		watermark time.Time,
+		value T,
+	) ([]*Window[T], bool) {
+		session := &Window[T]{Start: at, End: at.Add(gap)}
+		if !session.End.After(watermark) {
+			return open, false
+		}
+
+		// out of order values may bridge the gap between sessions.
+		var remaining []*Window[T]
+		for _, window := range open {
+			if window.Start.Before(session.End) && session.Start.Before(window.End) {
+				if window.Start.Before(session.Start) {
+					session.Start = window.Start
+				}
+				if window.End.After(session.End) {
+					session.End = window.End
+				}
+				session.Items = append(session.Items, window.Items...)
+			} else {
+				remaining = append(remaining, window)
+			}
+		}
+		session.Items = append(session.Items, value)
+
+		return append(remaining, session), true
+	}
+}
+
+// windowConfig describes how the window engine reads its input.
+type windowConfig[I, T any] struct {
+	assign windowAssigner[T]
+	// stamp returns the value held by an input, its event time, and the
+	// watermark it carries. the times are ignored for processing time.
+	stamp      func(input I) (T, time.Time, time.Time)
+	processing bool
+	lateness   time.Duration
+	// late receives the values that can't be placed. they are dropped if it is
+	// nil. it is closed with the output.
+	late chan<- T
+}
+
+// windowStream is the shared engine of the window stages.
+//
+// the watermark is the stage's notion of the current time: the clock for
+// processing time, or the latest watermark seen for event time. windows are
+// sent, in order of their end, once the watermark reaches their end plus the
+// allowed lateness.
+func windowStream[I, T any](
+	input <-chan *Result[I],
+	config windowConfig[I, T],
+	opts ...Option,
+) <-chan *Result[Window[T]] {
+	clock := newOptions(opts...).clock
+
+	return Stream(func(ctx context.Context, output chan<- *Result[Window[T]]) {
+		if config.late != nil {
+			defer close(config.late)
+		}
+		// the input is drained however the stage ends, including a recovered
+		// panic in eventTime.
+		defer func() {
+			go Drain(input)
+		}()
+
+		var open []*Window[T]
+		var watermark time.Time
+		// closed is the watermark less the allowed lateness. windows that end at
+		// or before it are complete.
+		closed := func() time.Time {
+			return watermark.Add(-config.lateness)
+		}
+
+		// processing time uses a timer to close windows without new values. it
+		// is only replaced when the earliest end changes, and the watermark is
+		// the current time whenever it is armed.
+		var timer <-chan time.Time
+		var armed time.Time
+		arm := func() {
+			if !config.processing || len(open) == 0 {
+				timer, armed = nil, time.Time{}
+				return
+			}
+			end := open[0].End.Add(config.lateness)
+			if !end.Equal(armed) {
+				timer, armed = clock.After(end.Sub(watermark)), end
+			}
+		}
+
+		// emit sends the windows that are complete.
+		emit := func() bool {
+			slices.SortStableFunc(open, func(a, b *Window[T]) int {
+				return a.End.Compare(b.End)
+			})
+			for len(open) > 0 && !open[0].End.After(closed()) {
+				if !send(ctx, output, NewResult(*open[0], nil)) {
+					return false
+				}
+				open = open[1:]
+			}
+			arm()
+			return true
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-timer:
+				armed = time.Time{}
+				if now.After(watermark) {
+					watermark = now
+				}
+				if !emit() {
+					return
+				}
+			case result, ok := <-input:
+				if !ok {
+					for _, window := range open {
+						if !send(ctx, output, NewResult(*window, nil)) {
+							return
+						}
+					}
+					return
+				}
+				if result.Error != nil {
+					if !send(ctx, output, NewResult(Window[T]{}, result.Error)) {
+						return
+					}
+					continue
+				}
+
+				value, at, mark := config.stamp(result.Value)
+				if config.processing {
+					at = clock.Now()
+					mark = at
+				}
+				if mark.After(watermark) {
+					watermark = mark
+				}
+
+				var placed bool
+				open, placed = config.assign(open, at, closed(), value)
+				if !placed && config.late != nil {
+					if !send(ctx, config.late, value) {
+						return
+					}
+				}
+				if !emit() {
+					return
+				}
+			}
+		}
+	}, opts...)
+}
@@ -0,0 +1,202 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// event is a value with an event time, in seconds.
+type event struct {
+	Name string
+	At   int
+}
+
+func eventTime(value event) time.Time {
+	return time.Unix(int64(value.At), 0)
+}
+
+func events(values ...event) <-chan *Result[event] {
+	return Stream(func(_ context.Context, output chan<- *Result[event]) {
+		for _, value := range values {
+			output <- NewResult(value, nil)
+		}
+	})
+}
+
+func window[T any](start, end int, items ...T) *Result[Window[T]] {
+	return NewResult(Window[T]{
+		Start: time.Unix(int64(start), 0),
+		End:   time.Unix(int64(end), 0),
+		Items: items,
+	}, nil)
+}
+
+func TestTumblingWindow(t *testing.T) {
+	t.Run("event time", func(t *testing.T) {
+		a, b, c, d := event{"a", 0}, event{"b", 1}, event{"c", 2}, event{"d", 5}
+		windows := TumblingWindow(events(a, b, c, d), 2*time.Second, eventTime)
+
+		require.Equal(t, window(0, 2, a, b), <-windows)
+		require.Equal(t, window(2, 4, c), <-windows)
+		require.Equal(t, window(4, 6, d), <-windows)
+		validateChannel(t, nil, false, windows)
+	})
+
+	t.Run("late values are dropped", func(t *testing.T) {
+		a, b, c := event{"a", 0}, event{"b", 3}, event{"c", 1}
+		windows := TumblingWindow(events(a, b, c), 2*time.Second, eventTime)
+
+		require.Equal(t, window(0, 2, a), <-windows)
+		require.Equal(t, window(2, 4, b), <-windows)
+		validateChannel(t, nil, false, windows)
+	})
+
+	t.Run("processing time", func(t *testing.T) {
+		clock := newFakeClock()
+		src := make(chan *Result[string])
+		defer close(src)
+		windows := TumblingWindow(src, time.Second, nil, WithClock(clock))
+
+		src <- NewResult("a", nil)
+		src <- NewResult("b", nil)
+		clock.WaitForReads(t, 2)
+		clock.Advance(time.Second)
+		require.Equal(t, window(0, 1, "a", "b"), <-windows)
+
+		// an idle period produces no empty windows.
+		clock.Advance(5 * time.Second)
+		src <- NewResult("c", nil)
+		clock.WaitForTimers(t, 1)
+		clock.Advance(time.Second)
+		require.Equal(t, window(6, 7, "c"), <-windows)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		err := errors.New("generic error")
+		src := Stream(func(_ context.Context, output chan<- *Result[event]) {
+			output <- NewResult(event{"a", 0}, nil)
+			output <- NewResult(event{}, err)
+			output <- NewResult(event{"b", 1}, nil)
+		})
+		windows := TumblingWindow(src, 2*time.Second, eventTime)
+
+		require.Equal(t, NewResult(Window[event]{}, err), <-windows)
+		require.Equal(t, window(0, 2, event{"a", 0}, event{"b", 1}), <-windows)
+		validateChannel(t, nil, false, windows)
+	})
+
+	t.Run("cancelable", func(t *testing.T) {
+		baseline := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		windows := TumblingWindow(counter(1000), 2*time.Second,
+			func(value int) time.Time {
+				return time.Unix(int64(value), 0)
+			},
+			WithContext(ctx), WithBufferSize(0))
+		require.Equal(t, window(0, 2, 0, 1), <-windows)
+		cancel()
+
+		requireNoLeaks(t, baseline)
+	})
+}
+
+func TestSlidingWindow(t *testing.T) {
+	a, b, c := event{"a", 0}, event{"b", 1}, event{"c", 2}
+	windows := SlidingWindow(events(a, b, c), 2*time.Second, time.Second, eventTime)
+
+	require.Equal(t, window(-1, 1, a), <-windows)
+	require.Equal(t, window(0, 2, a, b), <-windows)
+	require.Equal(t, window(1, 3, b, c), <-windows)
+	require.Equal(t, window(2, 4, c), <-windows)
+	validateChannel(t, nil, false, windows)
+}
+
+func TestSessionWindow(t *testing.T) {
+	t.Run("event time", func(t *testing.T) {
+		a, b, c, d := event{"a", 0}, event{"b", 2}, event{"c", 7}, event{"d", 8}
+		windows := SessionWindow(events(a, b, c, d), 3*time.Second, eventTime)
+
+		require.Equal(t, window(0, 5, a, b), <-windows)
+		require.Equal(t, window(7, 11, c, d), <-windows)
+		validateChannel(t, nil, false, windows)
+	})
+
+	t.Run("processing time", func(t *testing.T) {
+		clock := newFakeClock()
+		src := make(chan *Result[string])
+		defer close(src)
+		windows := SessionWindow(src, time.Second, nil, WithClock(clock))
+
+		src <- NewResult("a", nil)
+		clock.WaitForTimers(t, 1)
+		clock.Advance(500 * time.Millisecond)
+		// extends the session, and its timer.
+		src <- NewResult("b", nil)
+		clock.WaitForTimers(t, 2)
+		clock.Advance(999 * time.Millisecond)
+		select {
+		case value := <-windows:
+			require.FailNow(t, "closed early", value)
+		case <-time.After(5 * time.Millisecond):
+		}
+
+		clock.Advance(time.Millisecond)
+		require.Equal(t,
+			NewResult(Window[string]{
+				Start: time.Unix(0, 0),
+				End:   time.Unix(1, 500*int64(time.Millisecond)),
+				Items: []string{"a", "b"},
+			}, nil),
+			<-windows,
+		)
+	})
+}
+
+func TestEventTimeWindow(t *testing.T) {
+	t.Run("allowed lateness", func(t *testing.T) {
+		a, b, c, d, e := event{"a", 0}, event{"b", 2}, event{"c", 1},
+			event{"d", 3}, event{"e", 0}
+		windows, late := EventTimeWindow(
+			Watermark([]<-chan *Result[event]{events(a, b, c, d, e)}, eventTime),
+			Tumbling(2*time.Second),
+			time.Second,
+		)
+
+		// c is out of order, but within the allowed lateness.
+		require.Equal(t, window(0, 2, a, c), <-windows)
+		require.Equal(t, window(2, 4, b, d), <-windows)
+		validateChannel(t, nil, false, windows)
+
+		// e arrives after its window was sent.
+		require.Equal(t, e, <-late)
+		validateChannel(t, event{}, false, late)
+	})
+
+	t.Run("multiple sources", func(t *testing.T) {
+		windows, late := EventTimeWindow(
+			Watermark([]<-chan *Result[event]{
+				events(event{"a", 0}, event{"b", 3}),
+				events(event{"c", 1}, event{"d", 2}),
+			}, eventTime),
+			Session(2*time.Second),
+			0,
+		)
+
+		result := <-windows
+		require.NoError(t, result.Error)
+		require.Equal(t, time.Unix(0, 0), result.Value.Start)
+		require.Equal(t, time.Unix(5, 0), result.Value.End)
+		require.ElementsMatch(t,
+			[]event{{"a", 0}, {"b", 3}, {"c", 1}, {"d", 2}},
+			result.Value.Items,
+		)
+		validateChannel(t, nil, false, windows)
+		validateChannel(t, event{}, false, late)
+	})
+}